@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"kintai/internal/audit"
+	"kintai/internal/kinnosuke"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	auditMonth string
+	auditDM    bool
+	auditJSON  bool
+)
+
+// auditIssue は audit --json の1件分。
+type auditIssue struct {
+	Date      string     `json:"date"`
+	Kind      audit.Kind `json:"kind"`
+	Start     string     `json:"start,omitempty"`
+	Leave     string     `json:"leave,omitempty"`
+	LeaveType string     `json:"leave_type,omitempty"`
+	Approval  string     `json:"approval,omitempty"`
+}
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "月次勤怠から打刻漏れ（退社なし・出社なし・打刻なし）の出勤日を一覧する",
+	Long: `勤之助の月次勤怠を読み取り、前日までの出勤日のうち打刻が欠けている日を一覧します。
+所定休日と承認済みの全休は対象外です。打刻漏れがあれば終了コード 10 で終了します。`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		month, err := parseMonth(auditMonth)
+		if err != nil {
+			return err
+		}
+		records, err := kinnosuke.Timesheet(cmd.Context(), month)
+		if err != nil {
+			return err
+		}
+
+		// 当日はまだ退社していないのが普通なので、前日までを見る
+		now := time.Now().In(month.Location())
+		before := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, month.Location())
+		if end := month.AddDate(0, 1, 0); end.Before(before) {
+			before = end
+		}
+		issues := audit.Check(records, before)
+
+		if auditJSON {
+			out := make([]auditIssue, 0, len(issues))
+			for _, i := range issues {
+				out = append(out, auditIssue{
+					Date:      i.Date.Format("2006-01-02"),
+					Kind:      i.Kind,
+					Start:     i.Record.Start,
+					Leave:     i.Record.Leave,
+					LeaveType: i.Record.LeaveType,
+					Approval:  i.Record.Approval,
+				})
+			}
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(out); err != nil {
+				return err
+			}
+		} else {
+			fmt.Println(audit.Message(month, issues))
+		}
+
+		// Slack：自分宛ての DM に一覧を送る（打刻漏れがなければ送らない）
+		if auditDM && len(issues) > 0 {
+			sc, err := newSlackClient()
+			if err != nil {
+				return err
+			}
+			if err := sc.PostDM(cmd.Context(), audit.Message(month, issues)); err != nil {
+				return err
+			}
+			if !auditJSON {
+				fmt.Println("✔ Slack DM 送信完了")
+			}
+		}
+
+		if len(issues) > 0 {
+			return fmt.Errorf("%w: %d", audit.ErrIssuesFound, len(issues))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.Flags().StringVarP(&auditMonth, "month", "m", "", "対象の年月 YYYY-MM (省略時は今月)")
+	auditCmd.Flags().BoolVar(&auditDM, "dm", false, "打刻漏れがあれば Slack で自分宛てに DM する")
+	auditCmd.Flags().BoolVar(&auditJSON, "json", false, "JSONで出力する")
+
+	auditCmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		if _, err := parseMonth(auditMonth); err != nil {
+			return err
+		}
+		if auditDM {
+			return validateConfig("")
+		}
+		return validateConfig("kinnosuke")
+	}
+}
@@ -1,45 +1,88 @@
 package cmd
 
 import (
-	"context"
 	"fmt"
 	"os"
 	"strings"
 
 	"kintai/internal/auth"
+	"kintai/internal/config"
 
 	"github.com/spf13/cobra"
 )
 
+var (
+	authStore     string
+	authKinnosuke bool
+)
+
 var authCmd = &cobra.Command{
 	Use:     "auth",
 	Aliases: []string{"a"},
-	Short:   "Slack OAuth 2.0 で User Token を取得し .env に保存する",
+	Short:   "Slack OAuth 2.0 で User Token を取得し、シークレットストアに保存する",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openAuthStore()
+		if err != nil {
+			return err
+		}
+
+		if authKinnosuke {
+			return saveKinnosukePassword(store)
+		}
+
 		clientID := os.Getenv("SLACK_CLIENT_ID")
-		clientSecret := os.Getenv("SLACK_CLIENT_SECRET")
+		clientSecret, err := auth.LookupSecret("SLACK_CLIENT_SECRET")
+		if err != nil {
+			return err
+		}
 
 		if clientID == "" || clientSecret == "" {
-			return fmt.Errorf("SLACK_CLIENT_ID と SLACK_CLIENT_SECRET を .env またはシェル環境変数に設定してください")
+			return fmt.Errorf("%w: SLACK_CLIENT_ID と SLACK_CLIENT_SECRET を .env またはシェル環境変数に設定してください", config.ErrMissing)
 		}
 
-		token, err := auth.Run(context.Background(), clientID, clientSecret)
+		token, err := auth.Run(cmd.Context(), clientID, clientSecret)
 		if err != nil {
 			return err
 		}
 
-		// .env にトークンを保存
-		envPath := ".env"
-		if err := auth.UpsertEnvToken(envPath, "SLACK_TOKEN", token); err != nil {
-			return fmt.Errorf(".envへの書き込みに失敗: %w", err)
+		if err := store.Set("SLACK_TOKEN", token); err != nil {
+			return fmt.Errorf("%sへの書き込みに失敗: %w", store.Name(), err)
 		}
 
 		masked := maskToken(token)
-		fmt.Printf("✔ SLACK_TOKEN を .env に保存しました (%s)\n", masked)
+		fmt.Printf("✔ SLACK_TOKEN を %s に保存しました (%s)\n", store.Name(), masked)
 		return nil
 	},
 }
 
+// openAuthStore は --store（省略時は KN_SECRET_STORE）のシークレットストアを開く。
+func openAuthStore() (auth.SecretStore, error) {
+	if authStore == "" {
+		return auth.SecretStoreFromEnv()
+	}
+	return auth.OpenSecretStore(auth.SecretStoreOptions{
+		Backend:   authStore,
+		Namespace: os.Getenv("KN_PROFILE"),
+		FilePath:  os.Getenv("KN_SECRET_FILE"),
+	})
+}
+
+// saveKinnosukePassword は勤之助のパスワードを端末で入力させ、シークレットストアに保存する。
+func saveKinnosukePassword(store auth.SecretStore) error {
+	pass, err := auth.PromptSecret("勤之助のパスワード")
+	if err != nil {
+		return fmt.Errorf("パスワードの入力に失敗: %w", err)
+	}
+	if pass == "" {
+		return fmt.Errorf("%w: パスワードが空です", config.ErrMissing)
+	}
+	if err := store.Set("KIN_PASSWORD", pass); err != nil {
+		return fmt.Errorf("%sへの書き込みに失敗: %w", store.Name(), err)
+	}
+	fmt.Printf("✔ KIN_PASSWORD を %s に保存しました\n", store.Name())
+	return nil
+}
+
 // maskToken はトークンの先頭10文字だけ表示し、残りをマスクする。
 func maskToken(token string) string {
 	if len(token) <= 10 {
@@ -50,4 +93,6 @@ func maskToken(token string) string {
 
 func init() {
 	rootCmd.AddCommand(authCmd)
+	authCmd.Flags().StringVar(&authStore, "store", "", "保存先 auto|keyring|file|dotenv (default: KN_SECRET_STORE、未設定なら auto)")
+	authCmd.Flags().BoolVar(&authKinnosuke, "kinnosuke", false, "Slack認証の代わりに勤之助のパスワードを入力して保存する")
 }
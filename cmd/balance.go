@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"kintai/internal/kinnosuke"
+
+	"github.com/spf13/cobra"
+)
+
+// errOvertimeExceeded は残業時間が警告の閾値を超えたことを表す。
+var errOvertimeExceeded = errors.New("overtime exceeded threshold")
+
+var (
+	balanceMonth     string
+	balanceWarnHours float64
+	balanceJSON      bool
+)
+
+// balanceReport は balance --json の出力。
+type balanceReport struct {
+	PaidLeaveRemaining   float64 `json:"paid_leave_remaining"`
+	PaidLeaveCarriedOver float64 `json:"paid_leave_carried_over"`
+	Month                string  `json:"month"`
+	OvertimeMinutes      int     `json:"overtime_minutes"`
+	WarnMinutes          int     `json:"warn_minutes,omitempty"`
+	Exceeded             bool    `json:"exceeded"`
+}
+
+var balanceCmd = &cobra.Command{
+	Use:   "balance",
+	Short: "勤之助の有休の残日数と、今月の残業時間の累計を表示する",
+	Long: `勤之助から有休の残日数・繰越日数と、月次勤怠の残業時間の累計を読み取って表示します。
+--warn-hours（または設定ファイルの overtime.warn_hours）を指定すると、
+残業時間がその時間以上のときに警告し、終了コード 12 で終了します。`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		month, err := parseMonth(balanceMonth)
+		if err != nil {
+			return err
+		}
+		b, err := kinnosuke.FetchBalance(cmd.Context(), month)
+		if err != nil {
+			return err
+		}
+
+		warnHours := activeProfile.Overtime.WarnHours
+		if cmd.Flags().Changed("warn-hours") {
+			warnHours = balanceWarnHours
+		}
+		warn := time.Duration(warnHours * float64(time.Hour))
+		exceeded := warn > 0 && b.Overtime >= warn
+
+		if balanceJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(balanceReport{
+				PaidLeaveRemaining:   b.PaidLeaveRemaining,
+				PaidLeaveCarriedOver: b.PaidLeaveCarriedOver,
+				Month:                b.Month.Format("2006-01"),
+				OvertimeMinutes:      int(b.Overtime / time.Minute),
+				WarnMinutes:          int(warn / time.Minute),
+				Exceeded:             exceeded,
+			}); err != nil {
+				return err
+			}
+		} else {
+			fmt.Printf("有休残日数: %g日（うち繰越 %g日）\n", b.PaidLeaveRemaining, b.PaidLeaveCarriedOver)
+			if warn > 0 {
+				fmt.Printf("%s の残業: %s / %s (%d%%)\n", b.Month.Format("2006-01"),
+					orZeroHM(formatHM(b.Overtime)), formatHM(warn), int(b.Overtime*100/warn))
+			} else {
+				fmt.Printf("%s の残業: %s\n", b.Month.Format("2006-01"), orZeroHM(formatHM(b.Overtime)))
+			}
+			if exceeded {
+				fmt.Printf("⚠ 残業時間が %s を超えています\n", formatHM(warn))
+			}
+		}
+
+		if exceeded {
+			return fmt.Errorf("%w: %s >= %s", errOvertimeExceeded, formatHM(b.Overtime), formatHM(warn))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(balanceCmd)
+	balanceCmd.Flags().StringVarP(&balanceMonth, "month", "m", "", "残業時間を集計する年月 YYYY-MM (省略時は今月)")
+	balanceCmd.Flags().Float64Var(&balanceWarnHours, "warn-hours", 0, "残業時間がこの時間以上なら警告する (省略時は設定ファイルの overtime.warn_hours、0 なら警告しない)")
+	balanceCmd.Flags().BoolVar(&balanceJSON, "json", false, "JSONで出力する")
+
+	balanceCmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		if _, err := parseMonth(balanceMonth); err != nil {
+			return err
+		}
+		if balanceWarnHours < 0 {
+			return errors.New("--warn-hours must not be negative")
+		}
+		return validateConfig("kinnosuke")
+	}
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"kintai/internal/kinnosuke"
+	"kintai/internal/slackkintai"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	breakOnly   string
+	breakForce  bool
+	breakAtomic bool
+)
+
+var breakCmd = &cobra.Command{
+	Use:     "break",
+	Aliases: []string{"b"},
+	Short:   "休憩入り・休憩戻りを打刻する",
+}
+
+var breakStartCmd = &cobra.Command{
+	Use:     "start",
+	Aliases: []string{"s"},
+	Short:   "休憩入りを打刻して、設定があればSlackにリアクション・ステータスを付ける",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// 勤怠ノ助：休憩入り / Slack：開始スレにリアクションし、ステータスを休憩中にする
+		return runBreak(cmd.Context(), "休憩入り", kinnosuke.StampBreakStart, (*slackkintai.Client).ReactBreakStart, true)
+	},
+}
+
+var breakEndCmd = &cobra.Command{
+	Use:     "end",
+	Aliases: []string{"e"},
+	Short:   "休憩戻りを打刻して、設定があればSlackにリアクションし、ステータスを戻す",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// 勤怠ノ助：休憩戻り / Slack：開始スレにリアクションし、休憩中のステータスを消す
+		return runBreak(cmd.Context(), "休憩戻り", kinnosuke.StampBreakEnd, (*slackkintai.Client).ReactBreakEnd, false)
+	},
+}
+
+// runBreak は休憩の打刻・リアクション・ステータスの変更を実行する。
+// setStatus なら休憩中のステータスを設定し、そうでなければ消す。
+func runBreak(
+	ctx context.Context,
+	label string,
+	stamp func(context.Context, kinnosuke.StampOptions) (string, error),
+	react func(*slackkintai.Client, context.Context, bool) (slackkintai.Reaction, error),
+	setStatus bool,
+) error {
+	var legs []leg
+	if breakOnly == "" || breakOnly == "kinnosuke" {
+		legs = append(legs, stampLeg(label, func(ctx context.Context) (string, error) {
+			// 休憩は1日に何度でも取れるので、打刻済みでも打刻する
+			return stamp(ctx, kinnosuke.StampOptions{})
+		}))
+	}
+	if breakOnly == "" || breakOnly == "slack" {
+		cfg, err := slackConfig()
+		if err != nil {
+			return err
+		}
+		legs = append(legs,
+			reactLeg(label, func(ctx context.Context, sc *slackkintai.Client) (slackkintai.Reaction, error) {
+				return react(sc, ctx, breakForce)
+			}),
+			// 休憩用のステータスを設定していない人の手動ステータスは消さない
+			statusLeg("休憩", cfg.BreakStatus, setStatus),
+		)
+	}
+	return runPipeline(ctx, os.Stdout, legs, breakAtomic)
+}
+
+func init() {
+	rootCmd.AddCommand(breakCmd)
+	breakCmd.AddCommand(breakStartCmd, breakEndCmd)
+	breakCmd.PersistentFlags().StringVarP(&breakOnly, "only", "o", "", "kinnosuke(kin)|slack(s) (省略時は両方実行)")
+	breakCmd.PersistentFlags().BoolVarP(&breakForce, "force", "f", false, "リアクション済みでもリアクションする")
+	breakCmd.PersistentFlags().BoolVar(&breakAtomic, "atomic", false, "Slack、勤之助の順に実行し、失敗したら済んだ Slack の操作を取り消す")
+
+	// ネットワークに接続する前に設定をまとめて検証する
+	preRun := func(cmd *cobra.Command, args []string) error {
+		breakOnly = normalizeOnly(breakOnly)
+		if err := validateOnly(breakOnly); err != nil {
+			return err
+		}
+		return validateConfig(breakOnly)
+	}
+	breakStartCmd.PreRunE = preRun
+	breakEndCmd.PreRunE = preRun
+}
@@ -0,0 +1,245 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+
+	"kintai/internal/auth"
+	"kintai/internal/kinnosuke"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	configEnvPath string
+	configReveal  bool
+)
+
+var reEnvKey = regexp.MustCompile(`^[A-Z_][A-Z0-9_]*$`)
+
+// secretKeys は表示時にマスクするキー。
+var secretKeys = []string{"KIN_PASSWORD", "SLACK_TOKEN", "SLACK_CLIENT_SECRET", "KN_SECRET_PASSPHRASE", "KN_SESSION_KEY"}
+
+// storedSecretKeys は set / unset / get で .env の代わりにシークレットストアを使うキー（auth.LookupSecret で読むもの）。
+var storedSecretKeys = []string{"KIN_PASSWORD", "SLACK_TOKEN", "SLACK_CLIENT_SECRET", "KN_SESSION_KEY"}
+
+var configCmd = &cobra.Command{
+	Use:     "config",
+	Aliases: []string{"c"},
+	Short:   ".env の設定を表示・変更・検証する",
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get KEY",
+	Short: "設定値を表示する（秘密情報はマスク）",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key := args[0]
+		entries, err := auth.ListEnv(configEnvPath)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if e.Key == key {
+				fmt.Println(displayValue(e.Key, e.Value))
+				return nil
+			}
+		}
+		if slices.Contains(storedSecretKeys, key) {
+			store, err := openConfigStore()
+			if err != nil {
+				return err
+			}
+			v, err := store.Get(key)
+			if err == nil {
+				fmt.Println(displayValue(key, v))
+				return nil
+			}
+			if !errors.Is(err, auth.ErrSecretNotFound) {
+				return fmt.Errorf("%s から %s を読み込めません: %w", store.Name(), key, err)
+			}
+			return fmt.Errorf("%s は %s にも %s にも設定されていません", key, configEnvPath, store.Name())
+		}
+		return fmt.Errorf("%s は %s に設定されていません", key, configEnvPath)
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set KEY VALUE",
+	Short: "設定値を保存する（コメント・順序は保持）",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, value := args[0], args[1]
+		if err := checkEnvKey(key); err != nil {
+			return err
+		}
+		if slices.Contains(storedSecretKeys, key) {
+			// 秘密情報は .env に平文で書かず、シークレットストア（KN_SECRET_STORE）に保存する
+			store, err := openConfigStore()
+			if err != nil {
+				return err
+			}
+			if err := store.Set(key, value); err != nil {
+				return fmt.Errorf("%sへの書き込みに失敗: %w", store.Name(), err)
+			}
+			fmt.Printf("✔ %s を %s に保存しました (%s)\n", key, store.Name(), displayValue(key, value))
+			return nil
+		}
+		if err := auth.UpsertEnvToken(configEnvPath, key, value); err != nil {
+			return fmt.Errorf(".envへの書き込みに失敗: %w", err)
+		}
+		fmt.Printf("✔ %s を %s に保存しました (%s)\n", key, configEnvPath, displayValue(key, value))
+		return nil
+	},
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset KEY",
+	Short: "設定値を削除する",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key := args[0]
+		if err := checkEnvKey(key); err != nil {
+			return err
+		}
+		if err := auth.RemoveEnvKey(configEnvPath, key); err != nil {
+			return fmt.Errorf(".envへの書き込みに失敗: %w", err)
+		}
+		fmt.Printf("✔ %s を %s から削除しました\n", key, configEnvPath)
+		if slices.Contains(storedSecretKeys, key) {
+			store, err := openConfigStore()
+			if err != nil {
+				return err
+			}
+			if err := store.Delete(key); err != nil {
+				return fmt.Errorf("%sからの削除に失敗: %w", store.Name(), err)
+			}
+			fmt.Printf("✔ %s を %s から削除しました\n", key, store.Name())
+		}
+		return nil
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "設定値を一覧表示する（秘密情報はマスク）",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := auth.ListEnv(configEnvPath)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			fmt.Printf("%s=%s\n", e.Key, displayValue(e.Key, e.Value))
+		}
+		return nil
+	},
+}
+
+var configDoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "設定を検証し、勤之助へのログインと Slack の auth.test を試す",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var failed []error
+		report := func(label, ok string, err error) {
+			if err != nil {
+				fmt.Printf("✘ %s: %v\n", label, err)
+				failed = append(failed, err)
+				return
+			}
+			fmt.Printf("✔ %s: %s\n", label, ok)
+		}
+
+		// 勤之助：設定 → ログイン
+		if err := validateConfig("kinnosuke"); err != nil {
+			report("勤之助の設定", "", err)
+		} else {
+			var name string
+			page, err := kinnosuke.Today(cmd.Context())
+			if err == nil {
+				name = page.UserName
+			}
+			report("勤之助ログイン", name, err)
+		}
+
+		// Slack：設定 → auth.test
+		if err := validateConfig("slack"); err != nil {
+			report("Slackの設定", "", err)
+		} else {
+			user, team, err := slackAuthTest(cmd.Context())
+			report("Slack auth.test", user+" @ "+team, err)
+		}
+
+		// 通知先：設定のみ（doctor では通知を送らない）
+		if len(activeProfile.Notifiers) > 0 {
+			var err error
+			if errs := validateNotifiers(""); len(errs) > 0 {
+				err = &configError{errs: errs}
+			}
+			report("通知先の設定", fmt.Sprintf("%d 件", len(activeProfile.Notifiers)), err)
+		}
+
+		if len(failed) > 0 {
+			return &doctorError{errs: failed}
+		}
+		return nil
+	},
+}
+
+// doctorError は doctor で失敗したチェックをまとめたエラー。
+// 各エラーは表示済みなので Error() は件数だけを返す。
+type doctorError struct {
+	errs []error
+}
+
+func (e *doctorError) Error() string {
+	return fmt.Sprintf("%d 件のチェックに失敗しました", len(e.errs))
+}
+
+func (e *doctorError) Unwrap() []error { return e.errs }
+
+func slackAuthTest(ctx context.Context) (user, team string, err error) {
+	sc, err := newSlackClient()
+	if err != nil {
+		return "", "", err
+	}
+	return sc.AuthTest(ctx)
+}
+
+// displayValue は秘密情報なら maskToken でマスクした値を返す（--reveal 指定時はそのまま）。
+func displayValue(key, value string) string {
+	if configReveal || !slices.Contains(secretKeys, key) {
+		return value
+	}
+	return maskToken(value)
+}
+
+// openConfigStore は KN_SECRET_STORE のシークレットストアを開く。dotenv なら --env-file の .env を使う。
+func openConfigStore() (auth.SecretStore, error) {
+	return auth.OpenSecretStore(auth.SecretStoreOptions{
+		Backend:   os.Getenv("KN_SECRET_STORE"),
+		Namespace: os.Getenv("KN_PROFILE"),
+		FilePath:  os.Getenv("KN_SECRET_FILE"),
+		EnvPath:   configEnvPath,
+	})
+}
+
+func checkEnvKey(key string) error {
+	if !reEnvKey.MatchString(key) {
+		return fmt.Errorf("invalid key %q: 英大文字・数字・_ のみ使えます", key)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configGetCmd, configSetCmd, configUnsetCmd, configListCmd, configDoctorCmd)
+	configCmd.PersistentFlags().StringVar(&configEnvPath, "env-file", ".env", "対象の .env ファイル")
+	configGetCmd.Flags().BoolVar(&configReveal, "reveal", false, "秘密情報をマスクせずに表示する")
+	configListCmd.Flags().BoolVar(&configReveal, "reveal", false, "秘密情報をマスクせずに表示する")
+}
@@ -2,64 +2,52 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 	"os"
 
 	"kintai/internal/kinnosuke"
-	"kintai/internal/slackkintai"
+	"kintai/internal/notify"
 
 	"github.com/spf13/cobra"
 )
 
-var endOnly string
+var (
+	endOnly   string
+	endForce  bool
+	endAtomic bool
+)
 
 var endCmd = &cobra.Command{
 	Use:     "end",
 	Aliases: []string{"e"},
-	Short:   "退社打刻して、Slackの業務終了スレにリアクションする",
+	Short:   "退社打刻して、Slackの業務終了スレへのリアクションなどで通知する",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// --only バリデーション
-		if endOnly != "" && endOnly != "kinnosuke" && endOnly != "slack" {
-			return fmt.Errorf("--only(-o) must be kinnosuke(kin) or slack(s)")
-		}
-
-		// 勤怠ノ助：退社
-		if endOnly == "" || endOnly == "kinnosuke" {
-			t, err := kinnosuke.StampEnd()
-			if err != nil {
-				return err
-			}
-			fmt.Printf("✔ 退社完了 (%s)\n", t)
-		}
-
-		// Slack：終了スレにリアクション
-		if endOnly == "" || endOnly == "slack" {
-			if err := slackkintai.ReactEnd(context.Background()); err != nil {
-				return err
-			}
-			fmt.Println("✔ Slackリアクション完了 (終了)")
-		}
-
-		return nil
+		// 勤怠ノ助の退社打刻と、通知先（Slack の終了スレへのリアクションなど）への通知を並行に実行する
+		return stampAndNotify{
+			only:   endOnly,
+			force:  endForce,
+			atomic: endAtomic,
+			label:  "退社",
+			thread: "終了",
+			stamp:  kinnosuke.StampEnd,
+			send: func(ctx context.Context, n notify.Notifier) error {
+				return n.OnEnd(ctx)
+			},
+		}.run(cmd.Context(), os.Stdout)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(endCmd)
-	endCmd.Flags().StringVarP(&endOnly, "only", "o", "", "kinnosuke(kin)|slack(s) (省略時は両方実行)")
+	endCmd.Flags().StringVarP(&endOnly, "only", "o", "", "kinnosuke(kin)|slack(s)|notify(n)（slack は Slack の通知先だけ、notify は通知先すべて） (省略時は両方実行)")
+	endCmd.Flags().BoolVarP(&endForce, "force", "f", false, "打刻済み・リアクション済みでも実行する")
+	endCmd.Flags().BoolVar(&endAtomic, "atomic", false, "取り消せる通知（Slack）、勤之助、その他の通知の順に実行し、打刻までに失敗したら済んだ Slack の操作を取り消す")
 
-	// envの未設定を早めに気づけるように（任意）
+	// ネットワークに接続する前に設定をまとめて検証する
 	endCmd.PreRunE = func(cmd *cobra.Command, args []string) error {
 		endOnly = normalizeOnly(endOnly)
-		if endOnly == "" || endOnly == "kinnosuke" {
-			_ = os.Getenv("KIN_COMPANYCD")
-			_ = os.Getenv("KIN_LOGINCD")
-			_ = os.Getenv("KIN_PASSWORD")
-		}
-		if endOnly == "" || endOnly == "slack" {
-			_ = os.Getenv("SLACK_TOKEN")
-			_ = os.Getenv("SLACK_CHANNEL")
+		if err := validateNotifyOnly(endOnly); err != nil {
+			return err
 		}
-		return nil
+		return validateNotifyConfig(endOnly)
 	}
 }
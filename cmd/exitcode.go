@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+
+	"kintai/internal/audit"
+	"kintai/internal/config"
+	"kintai/internal/kinnosuke"
+	"kintai/internal/slackkintai"
+)
+
+// プロセスの終了コード。cron やシェルのラッパーが失敗の種類で分岐できるようにする。
+const (
+	exitOK                  = 0
+	exitError               = 1   // 下記以外のエラー（ネットワークエラー・フラグ誤りなど）
+	exitConfig              = 2   // 必要な設定が足りない・形式が正しくない
+	exitUnauthorized        = 3   // 勤之助にログインできない
+	exitCSRFNotFound        = 4   // 勤之助の CSRF トークンが取れない
+	exitStampNotConfirmed   = 5   // 打刻後に打刻時刻を確認できない
+	exitAlreadyStamped      = 6   // 打刻済みのため打刻しなかった
+	exitReminderNotFound    = 7   // Slack のリマインダーが見つからない
+	exitReminderAmbiguous   = 8   // Slack のリマインダーが複数あり特定できない
+	exitTimesheetNotFound   = 9   // 勤之助の月次勤怠・休暇の画面の表や申請フォームが読み取れない
+	exitAuditIssues         = 10  // kn audit で打刻漏れが見つかった
+	exitRequestRejected     = 11  // 勤之助が申請（打刻修正・休暇）を受け付けなかった
+	exitOvertimeExceeded    = 12  // kn balance で残業時間が警告の閾値を超えた
+	exitTimeout             = 13  // --timeout の時間内に終わらなかった
+	exitPartialFailure      = 14  // 勤之助・通知先の一部だけが失敗した
+	exitStampButtonNotFound = 15  // 勤之助のトップページに打刻しようとした種別のボタンが無い
+	exitRequestNotConfirmed = 16  // 申請（打刻修正・休暇）の後に完了を確認できない
+	exitInterrupted         = 130 // Ctrl-C などで中断した（シェルの慣習に合わせる）
+)
+
+var exitCodes = []struct {
+	target error
+	code   int
+}{
+	{config.ErrMissing, exitConfig},
+	{config.ErrInvalid, exitConfig},
+	{config.ErrProfileNotFound, exitConfig},
+	{kinnosuke.ErrUnauthorized, exitUnauthorized},
+	{kinnosuke.ErrCSRFNotFound, exitCSRFNotFound},
+	{kinnosuke.ErrStampNotConfirmed, exitStampNotConfirmed},
+	{kinnosuke.ErrStampButtonNotFound, exitStampButtonNotFound},
+	{slackkintai.ErrReminderNotFound, exitReminderNotFound},
+	{slackkintai.ErrReminderAmbiguous, exitReminderAmbiguous},
+	{kinnosuke.ErrTimesheetNotFound, exitTimesheetNotFound},
+	{kinnosuke.ErrLeaveListNotFound, exitTimesheetNotFound},
+	{kinnosuke.ErrLeaveBalanceNotFound, exitTimesheetNotFound},
+	{kinnosuke.ErrRequestFormNotFound, exitTimesheetNotFound},
+	{audit.ErrIssuesFound, exitAuditIssues},
+	{kinnosuke.ErrRequestRejected, exitRequestRejected},
+	{kinnosuke.ErrRequestNotConfirmed, exitRequestNotConfirmed},
+	{errOvertimeExceeded, exitOvertimeExceeded},
+	{context.DeadlineExceeded, exitTimeout},
+	{context.Canceled, exitInterrupted},
+}
+
+// exitCode は err に対応する終了コードを返す。
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+	// 一部だけの失敗は、失敗した側の種類より先に判定する
+	var legs *legsError
+	if errors.As(err, &legs) && legs.partial() {
+		return exitPartialFailure
+	}
+	var already *kinnosuke.ErrAlreadyStamped
+	if errors.As(err, &already) {
+		return exitAlreadyStamped
+	}
+	for _, ec := range exitCodes {
+		if errors.Is(err, ec.target) {
+			return ec.code
+		}
+	}
+	return exitError
+}
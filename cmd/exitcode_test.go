@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"kintai/internal/audit"
+	"kintai/internal/config"
+	"kintai/internal/kinnosuke"
+	"kintai/internal/slackkintai"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, exitOK},
+		{errors.New("boom"), exitError},
+		{fmt.Errorf("%w: KIN_PASSWORD", config.ErrMissing), exitConfig},
+		{fmt.Errorf("%w: SLACK_TOKEN", config.ErrMissing), exitConfig},
+		{&configError{errs: []error{fmt.Errorf("%w: SLACK_TOKEN must be ...", config.ErrInvalid)}}, exitConfig},
+		{fmt.Errorf("login: %w", kinnosuke.ErrUnauthorized), exitUnauthorized},
+		{kinnosuke.ErrCSRFNotFound, exitCSRFNotFound},
+		{fmt.Errorf("%w: x", kinnosuke.ErrStampNotConfirmed), exitStampNotConfirmed},
+		{fmt.Errorf("%w: 休憩入り", kinnosuke.ErrStampButtonNotFound), exitStampButtonNotFound},
+		{&kinnosuke.ErrAlreadyStamped{Label: "出社", Time: "09:00"}, exitAlreadyStamped},
+		{fmt.Errorf("%w: x", slackkintai.ErrReminderNotFound), exitReminderNotFound},
+		{fmt.Errorf("%w: x", slackkintai.ErrReminderAmbiguous), exitReminderAmbiguous},
+		{fmt.Errorf("%w: header row not found", kinnosuke.ErrTimesheetNotFound), exitTimesheetNotFound},
+		{kinnosuke.ErrLeaveListNotFound, exitTimesheetNotFound},
+		{fmt.Errorf("%w: 有休残日数 not found", kinnosuke.ErrLeaveBalanceNotFound), exitTimesheetNotFound},
+		{fmt.Errorf("%w: 3", audit.ErrIssuesFound), exitAuditIssues},
+		{fmt.Errorf("%w: 締め処理済み", kinnosuke.ErrRequestRejected), exitRequestRejected},
+		{fmt.Errorf("timesheet form: %w: missing 理由", kinnosuke.ErrRequestFormNotFound), exitTimesheetNotFound},
+		{fmt.Errorf("%w: timesheet request was not confirmed", kinnosuke.ErrRequestNotConfirmed), exitRequestNotConfirmed},
+		{fmt.Errorf("%w: 46:00 >= 45:00", errOvertimeExceeded), exitOvertimeExceeded},
+		{fmt.Errorf("GET top: %w", context.DeadlineExceeded), exitTimeout},
+		{fmt.Errorf("stamp failed: %w", context.Canceled), exitInterrupted},
+	}
+	for _, tt := range tests {
+		if got := exitCode(tt.err); got != tt.want {
+			t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"kintai/internal/kinnosuke"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	fixDate   string
+	fixStart  string
+	fixEnd    string
+	fixReason string
+	fixDryRun bool
+)
+
+var fixCmd = &cobra.Command{
+	Use:   "fix",
+	Short: "勤之助に打刻修正を申請する",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req, err := fixRequest()
+		if err != nil {
+			return err
+		}
+		payload, err := kinnosuke.RequestFix(cmd.Context(), req, kinnosuke.SubmitOptions{DryRun: fixDryRun})
+		if err != nil {
+			return err
+		}
+
+		if fixDryRun {
+			fmt.Println("- dry-run: 次の内容を送信します（送信はしていません）")
+			for _, k := range slices.Sorted(maps.Keys(payload)) {
+				fmt.Printf("  %s=%s\n", k, payload[k])
+			}
+			return nil
+		}
+		fmt.Printf("✔ 打刻修正を申請しました (%s 出社 %s / 退社 %s)\n",
+			fixDate, orUnchanged(fixStart), orUnchanged(fixEnd))
+		return nil
+	},
+}
+
+// fixRequest はフラグから申請内容を組み立てて検証する。
+func fixRequest() (kinnosuke.FixRequest, error) {
+	loc, _ := time.LoadLocation("Asia/Tokyo")
+	date, err := time.ParseInLocation("2006-01-02", fixDate, loc)
+	if err != nil {
+		return kinnosuke.FixRequest{}, fmt.Errorf("--date must be YYYY-MM-DD: %q", fixDate)
+	}
+	req := kinnosuke.FixRequest{Date: date, Start: fixStart, End: fixEnd, Reason: fixReason}
+	if err := req.Validate(); err != nil {
+		return kinnosuke.FixRequest{}, err
+	}
+	return req, nil
+}
+
+func orUnchanged(t string) string {
+	if t == "" {
+		return "変更なし"
+	}
+	return t
+}
+
+func init() {
+	rootCmd.AddCommand(fixCmd)
+	fixCmd.Flags().StringVar(&fixDate, "date", "", "修正する日 YYYY-MM-DD (required)")
+	fixCmd.Flags().StringVar(&fixStart, "start", "", "正しい出社時刻 HH:MM")
+	fixCmd.Flags().StringVar(&fixEnd, "end", "", "正しい退社時刻 HH:MM")
+	fixCmd.Flags().StringVarP(&fixReason, "reason", "r", "", "申請理由 (required)")
+	fixCmd.Flags().BoolVar(&fixDryRun, "dry-run", false, "送信するフォームの内容を表示するだけで申請しない")
+	_ = fixCmd.MarkFlagRequired("date")
+	_ = fixCmd.MarkFlagRequired("reason")
+
+	// ネットワークに接続する前に申請内容と設定を検証する
+	fixCmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		if _, err := fixRequest(); err != nil {
+			return err
+		}
+		return validateConfig("kinnosuke")
+	}
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"kintai/internal/kinnosuke"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	leaveType   string
+	leaveDate   string
+	leaveHalf   string
+	leaveReason string
+	leaveDryRun bool
+
+	leaveMonth string
+	leaveJSON  bool
+)
+
+var leaveCmd = &cobra.Command{
+	Use:   "leave",
+	Short: "勤之助の休暇を申請・一覧する",
+}
+
+var leaveApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "勤之助に休暇を申請する",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req, err := leaveRequest()
+		if err != nil {
+			return err
+		}
+		payload, err := kinnosuke.ApplyLeave(cmd.Context(), req, kinnosuke.SubmitOptions{DryRun: leaveDryRun})
+		if err != nil {
+			return err
+		}
+
+		if leaveDryRun {
+			fmt.Println("- dry-run: 次の内容を送信します（送信はしていません）")
+			for _, k := range slices.Sorted(maps.Keys(payload)) {
+				fmt.Printf("  %s=%s\n", k, payload[k])
+			}
+			return nil
+		}
+		fmt.Printf("✔ 休暇を申請しました (%s %s%s)\n", leaveDate, kinnosuke.LeaveTypes[req.Type].Label, halfLabel(req.Half))
+		return nil
+	},
+}
+
+// leaveJSONRecord は leave list --json の1件分。
+type leaveJSONRecord struct {
+	Date     string `json:"date"`
+	Type     string `json:"type"`
+	Half     string `json:"half,omitempty"`
+	Approval string `json:"approval"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+var leaveListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "勤之助の休暇申請を一覧する",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		month, err := parseMonth(leaveMonth)
+		if err != nil {
+			return err
+		}
+		records, err := kinnosuke.Leaves(cmd.Context(), month)
+		if err != nil {
+			return err
+		}
+
+		if leaveJSON {
+			out := make([]leaveJSONRecord, 0, len(records))
+			for _, r := range records {
+				out = append(out, leaveJSONRecord{
+					Date:     r.Date.Format("2006-01-02"),
+					Type:     r.Type,
+					Half:     r.Half,
+					Approval: r.Approval,
+					Reason:   r.Reason,
+				})
+			}
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(out)
+		}
+
+		if len(records) == 0 {
+			fmt.Printf("%s の休暇申請はありません\n", month.Format("2006-01"))
+			return nil
+		}
+		for _, r := range records {
+			line := fmt.Sprintf("%s(%s) %s%s [%s]",
+				r.Date.Format("01/02"), kinnosuke.WeekdayJa(r.Date.Weekday()), r.Type, halfLabel(r.Half), r.Approval)
+			if r.Reason != "" {
+				line += " " + r.Reason
+			}
+			fmt.Println(line)
+		}
+		return nil
+	},
+}
+
+// leaveRequest はフラグから申請内容を組み立てて検証する。
+func leaveRequest() (kinnosuke.LeaveRequest, error) {
+	loc, _ := time.LoadLocation("Asia/Tokyo")
+	date, err := time.ParseInLocation("2006-01-02", leaveDate, loc)
+	if err != nil {
+		return kinnosuke.LeaveRequest{}, fmt.Errorf("--date must be YYYY-MM-DD: %q", leaveDate)
+	}
+	req := kinnosuke.LeaveRequest{Type: leaveType, Date: date, Half: leaveHalf, Reason: leaveReason}
+	if err := req.Validate(); err != nil {
+		return kinnosuke.LeaveRequest{}, err
+	}
+	return req, nil
+}
+
+func halfLabel(half string) string {
+	switch half {
+	case kinnosuke.HalfAM:
+		return "（午前半休）"
+	case kinnosuke.HalfPM:
+		return "（午後半休）"
+	case kinnosuke.HalfUnknown:
+		return "（取得単位不明）"
+	}
+	return ""
+}
+
+func init() {
+	rootCmd.AddCommand(leaveCmd)
+	leaveCmd.AddCommand(leaveApplyCmd, leaveListCmd)
+
+	types := slices.Sorted(maps.Keys(kinnosuke.LeaveTypes))
+	leaveApplyCmd.Flags().StringVar(&leaveType, "type", "", strings.Join(types, "|")+" (required)")
+	leaveApplyCmd.Flags().StringVar(&leaveDate, "date", "", "休暇の日 YYYY-MM-DD (required)")
+	leaveApplyCmd.Flags().StringVar(&leaveHalf, "half", "", "am|pm (省略時は全日)")
+	leaveApplyCmd.Flags().StringVarP(&leaveReason, "reason", "r", "", "申請理由")
+	leaveApplyCmd.Flags().BoolVar(&leaveDryRun, "dry-run", false, "送信するフォームの内容を表示するだけで申請しない")
+	_ = leaveApplyCmd.MarkFlagRequired("type")
+	_ = leaveApplyCmd.MarkFlagRequired("date")
+	_ = leaveApplyCmd.RegisterFlagCompletionFunc("type", cobra.FixedCompletions(types, cobra.ShellCompDirectiveNoFileComp))
+	_ = leaveApplyCmd.RegisterFlagCompletionFunc("half", cobra.FixedCompletions(
+		[]string{kinnosuke.HalfAM, kinnosuke.HalfPM}, cobra.ShellCompDirectiveNoFileComp))
+
+	leaveListCmd.Flags().StringVarP(&leaveMonth, "month", "m", "", "対象の年月 YYYY-MM (省略時は今月)")
+	leaveListCmd.Flags().BoolVar(&leaveJSON, "json", false, "JSONで出力する")
+
+	// ネットワークに接続する前に申請内容と設定を検証する
+	leaveApplyCmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		if _, err := leaveRequest(); err != nil {
+			return err
+		}
+		return validateConfig("kinnosuke")
+	}
+	leaveListCmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		if _, err := parseMonth(leaveMonth); err != nil {
+			return err
+		}
+		return validateConfig("kinnosuke")
+	}
+}
@@ -0,0 +1,325 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"kintai/internal/kinnosuke"
+	"kintai/internal/notify"
+	"kintai/internal/slackkintai"
+)
+
+// legStatus は1つの連携先（勤之助・通知先）の処理結果。
+type legStatus int
+
+const (
+	legOK legStatus = iota
+	legSkipped
+	legFailed
+	legRolledBack // 成功したが、--atomic で他の失敗にあわせて取り消した
+)
+
+func (s legStatus) mark() string {
+	switch s {
+	case legOK:
+		return "✔"
+	case legSkipped:
+		return "-"
+	case legRolledBack:
+		return "↩"
+	default:
+		return "✗"
+	}
+}
+
+// undoTimeout は取り消しにかける時間の上限。
+// 取り消しは Ctrl-C や --timeout で元の処理が中断された後にも行うので、元の context とは別に期限を付ける。
+const undoTimeout = 20 * time.Second
+
+// leg は連携先1つ分の処理（勤之助の打刻・通知・Slack のリアクションやステータスの変更）。
+// run は結果の説明（"出社 09:00" など）を返す。スキップしたときは *notify.SkipError を返す。
+// undo は成功した run を取り消す。nil なら取り消せない（勤之助の打刻など）。
+// bestEffort は取り消せない通知（Webhook など）で、atomic でも打刻の後に実行し、失敗しても他を取り消さない。
+type leg struct {
+	target     string
+	run        func(ctx context.Context) (string, error)
+	undo       func(ctx context.Context) error
+	bestEffort bool
+}
+
+// legResult は leg を実行した結果。
+type legResult struct {
+	target  string
+	status  legStatus
+	detail  string
+	err     error
+	elapsed time.Duration
+}
+
+// runLegs は legs を並行に実行し、legs と同じ順で結果を返す。
+// 1つが失敗しても他は止めない。
+func runLegs(ctx context.Context, legs []leg) []legResult {
+	results := make([]legResult, len(legs))
+	var wg sync.WaitGroup
+	for i, l := range legs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			detail, err := l.run(ctx)
+			r := legResult{target: l.target, detail: detail, err: err, elapsed: time.Since(start)}
+			var skip *notify.SkipError
+			switch {
+			case errors.As(err, &skip):
+				r.status, r.detail, r.err = legSkipped, skip.Reason, nil
+			case err != nil:
+				r.status, r.detail = legFailed, err.Error()
+			}
+			results[i] = r
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// runLegsAtomic は legs を1つずつ実行し、失敗したら残りを実行せず、成功したものを逆順に取り消す。
+// 取り消せる leg、取り消せない leg（打刻）、bestEffort の leg の順に実行するので、打刻が失敗しうる最後の手順になる。
+// bestEffort の leg は打刻が済んでから実行し、失敗しても失敗として表示するだけで取り消しはしない。
+func runLegsAtomic(ctx context.Context, legs []leg) []legResult {
+	results := make([]legResult, len(legs))
+	order := make([]int, 0, len(legs))
+	for _, pass := range []func(l leg) bool{
+		func(l leg) bool { return l.undo != nil },
+		func(l leg) bool { return l.undo == nil && !l.bestEffort },
+		func(l leg) bool { return l.undo == nil && l.bestEffort },
+	} {
+		for i, l := range legs {
+			if pass(l) {
+				order = append(order, i)
+			}
+		}
+	}
+
+	var done []int
+	failed := false
+	for _, i := range order {
+		if failed {
+			results[i] = legResult{target: legs[i].target, status: legSkipped, detail: "前の手順が失敗したため実行しませんでした"}
+			continue
+		}
+		results[i] = runLegs(ctx, legs[i:i+1])[0]
+		switch {
+		case legs[i].bestEffort:
+		case results[i].status == legOK:
+			done = append(done, i)
+		case results[i].status == legFailed:
+			failed = true
+		}
+	}
+	if !failed {
+		return results
+	}
+
+	undoCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), undoTimeout)
+	defer cancel()
+	for _, i := range slices.Backward(done) {
+		r := &results[i]
+		if legs[i].undo == nil {
+			r.detail += "（取り消せません）"
+			continue
+		}
+		if err := legs[i].undo(undoCtx); err != nil {
+			r.status, r.err = legFailed, fmt.Errorf("%s の取り消しに失敗: %w", r.target, err)
+			r.detail = r.err.Error()
+			continue
+		}
+		r.status, r.detail = legRolledBack, "取り消しました: "+r.detail
+	}
+	return results
+}
+
+// printLegs は結果を表にして w に書き出す。
+func printLegs(w io.Writer, results []legResult) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TARGET\tRESULT\tTIME\tDETAIL")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.target, r.status.mark(), r.elapsed.Round(10*time.Millisecond), r.detail)
+	}
+	_ = tw.Flush()
+}
+
+// legsError は失敗した連携先をまとめたエラー。
+// 各エラーは表に表示済みなので Error() は件数だけを返す。
+type legsError struct {
+	errs  []error
+	total int
+	// succeeded は成功したまま（取り消していない）の連携先があるか。
+	succeeded bool
+}
+
+func (e *legsError) Error() string {
+	return fmt.Sprintf("%d 件中 %d 件が失敗しました", e.total, len(e.errs))
+}
+
+func (e *legsError) Unwrap() []error { return e.errs }
+
+// partial は一部の連携先だけが失敗し、成功した連携先の結果が残っているかを返す。
+func (e *legsError) partial() bool { return e.succeeded }
+
+// legsErr は results に失敗があれば *legsError を返す。
+func legsErr(results []legResult) error {
+	var errs []error
+	succeeded := false
+	for _, r := range results {
+		if r.err != nil {
+			errs = append(errs, r.err)
+		}
+		if r.status == legOK {
+			succeeded = true
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return &legsError{errs: errs, total: len(results), succeeded: succeeded}
+}
+
+// runPipeline は legs を実行して結果の表を w に表示し、失敗があれば *legsError を返す。
+// atomic なら runLegsAtomic、そうでなければ runLegs で実行する。
+func runPipeline(ctx context.Context, w io.Writer, legs []leg, atomic bool) error {
+	var results []legResult
+	if atomic {
+		results = runLegsAtomic(ctx, legs)
+	} else {
+		results = runLegs(ctx, legs)
+	}
+	printLegs(w, results)
+	return legsErr(results)
+}
+
+// stampAndNotify は start / end に共通の、勤之助の打刻と通知先（Slack のリアクションなど）への通知の組み合わせ。
+type stampAndNotify struct {
+	only   string
+	force  bool
+	atomic bool
+	// label は打刻の表示名（出社・退社）、thread は通知の表示名（開始・終了）。
+	label, thread string
+	stamp         func(ctx context.Context, opts kinnosuke.StampOptions) (string, error)
+	send          func(ctx context.Context, n notify.Notifier) error
+}
+
+// run は勤之助と通知先を並行に実行し（atomic なら取り消せる通知先、勤之助、取り消せない通知先の順に実行し）、結果の表を表示する。
+// 取り消せない通知先は打刻の後に送るだけで、失敗しても打刻や Slack の操作は取り消さない。
+// KIN_SKIP_ON_LEAVE（プロファイルの skip_on_leave）が有効なら先に承認済みの休暇を確認し、全休なら両方スキップする。
+// 休暇の確認は補助的なものなので、失敗したときは警告だけ出して打刻・通知を続ける。
+func (s stampAndNotify) run(ctx context.Context, w io.Writer) error {
+	var legs []leg
+	if s.only == "" || s.only == "kinnosuke" {
+		if skipOnLeave() && !s.force {
+			leave, err := kinnosuke.LeaveToday(ctx)
+			switch {
+			case ctx.Err() != nil:
+				return ctx.Err()
+			case err != nil:
+				fmt.Fprintf(w, "⚠ 休暇の確認に失敗したため、確認せずに続けます: %v\n", err)
+			case leave != nil:
+				fmt.Fprintf(w, "- 本日は承認済みの休暇（%s）のためスキップ\n", leave.Type)
+				return nil
+			}
+		}
+		legs = append(legs, stampLeg(s.label, func(ctx context.Context) (string, error) {
+			return s.stamp(ctx, kinnosuke.StampOptions{Force: s.force})
+		}))
+	}
+	if s.only != "kinnosuke" {
+		legs = append(legs, notifierLegs(s.only, s.force, s.thread, s.send)...)
+	}
+	return runPipeline(ctx, w, legs, s.atomic)
+}
+
+// skipOnLeave は KIN_SKIP_ON_LEAVE が on / true / 1 かを返す。
+func skipOnLeave() bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("KIN_SKIP_ON_LEAVE"))) {
+	case "on", "true", "1":
+		return true
+	}
+	return false
+}
+
+// stampLeg は勤之助に打刻する leg を返す。打刻は取り消せない。
+func stampLeg(label string, stamp func(ctx context.Context) (string, error)) leg {
+	return leg{target: "kinnosuke", run: func(ctx context.Context) (string, error) {
+		t, err := stamp(ctx)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s %s", label, t), nil
+	}}
+}
+
+// reactLeg は Slack にリアクションする leg を返す。取り消すときはリアクションを外す。
+// react がゼロ値の Reaction を返したら、絵文字が未設定としてスキップする。
+func reactLeg(label string, react func(ctx context.Context, sc *slackkintai.Client) (slackkintai.Reaction, error)) leg {
+	var sc *slackkintai.Client
+	var reaction slackkintai.Reaction
+	return leg{
+		target: "slack",
+		run: func(ctx context.Context) (string, error) {
+			var err error
+			if sc, err = newSlackClient(); err != nil {
+				return "", err
+			}
+			reaction, err = react(ctx, sc)
+			if err := notify.ReactionResult(label, reaction, err); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("リアクション完了 (%s: :%s:)", label, reaction.Emoji), nil
+		},
+		undo: func(ctx context.Context) error {
+			return sc.RemoveReaction(ctx, reaction)
+		},
+	}
+}
+
+// statusLeg は Slack のステータスを変更する leg を返す。
+// set なら st を設定し、取り消すときはステータスを消す。set でなければステータスを消し、取り消すときは st を設定し直す。
+// st が未設定ならスキップする（手動で設定したステータスは消さない）。
+func statusLeg(label string, st slackkintai.UserStatus, set bool) leg {
+	var sc *slackkintai.Client
+	apply := func(ctx context.Context, set bool) error {
+		if set {
+			return sc.SetStatus(ctx, st)
+		}
+		return sc.ClearStatus(ctx)
+	}
+	return leg{
+		target: "slack-status",
+		run: func(ctx context.Context) (string, error) {
+			if st.IsZero() {
+				return "", &notify.SkipError{Reason: fmt.Sprintf("%sのステータスが未設定のためスキップ", label)}
+			}
+			var err error
+			if sc, err = newSlackClient(); err != nil {
+				return "", err
+			}
+			if err := apply(ctx, set); err != nil {
+				return "", err
+			}
+			if set {
+				return fmt.Sprintf("ステータス設定 (:%s: %s)", st.Emoji, st.Text), nil
+			}
+			return "ステータス解除", nil
+		},
+		undo: func(ctx context.Context) error {
+			return apply(ctx, !set)
+		},
+	}
+}
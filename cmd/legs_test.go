@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"kintai/internal/notify"
+)
+
+func TestRunLegs(t *testing.T) {
+	boom := errors.New("boom")
+	sleep := func(d time.Duration, detail string, err error) func(context.Context) (string, error) {
+		return func(context.Context) (string, error) {
+			time.Sleep(d)
+			return detail, err
+		}
+	}
+
+	start := time.Now()
+	results := runLegs(t.Context(), []leg{
+		{target: "kinnosuke", run: sleep(100*time.Millisecond, "", boom)},
+		{target: "slack", run: sleep(100*time.Millisecond, "リアクション完了 (開始)", nil)},
+		{target: "other", run: sleep(0, "", &notify.SkipError{Reason: "リアクション済みのためスキップ"})},
+	})
+	if d := time.Since(start); d >= 190*time.Millisecond {
+		t.Errorf("runLegs took %v, want legs to run concurrently", d)
+	}
+
+	want := []struct {
+		target string
+		status legStatus
+		detail string
+	}{
+		{"kinnosuke", legFailed, "boom"},
+		{"slack", legOK, "リアクション完了 (開始)"},
+		{"other", legSkipped, "リアクション済みのためスキップ"},
+	}
+	for i, w := range want {
+		r := results[i]
+		if r.target != w.target || r.status != w.status || r.detail != w.detail {
+			t.Errorf("results[%d] = %+v, want %+v", i, r, w)
+		}
+	}
+
+	err := legsErr(results)
+	var le *legsError
+	if !errors.As(err, &le) || !le.partial() || !errors.Is(err, boom) {
+		t.Fatalf("legsErr = %v, want partial failure wrapping boom", err)
+	}
+	if got := exitCode(err); got != exitPartialFailure {
+		t.Errorf("exitCode = %d, want %d", got, exitPartialFailure)
+	}
+
+	var buf bytes.Buffer
+	printLegs(&buf, results)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "TARGET") ||
+		!strings.HasPrefix(lines[1], "kinnosuke  ✗") || !strings.HasSuffix(lines[2], "リアクション完了 (開始)") {
+		t.Errorf("printLegs =\n%s", buf.String())
+	}
+}
+
+func TestLegsErr(t *testing.T) {
+	if err := legsErr([]legResult{{status: legOK}, {status: legSkipped}}); err != nil {
+		t.Errorf("legsErr = %v, want nil", err)
+	}
+
+	// すべて失敗したときは、失敗の種類の終了コードを返す
+	err := legsErr([]legResult{{status: legFailed, err: context.DeadlineExceeded}})
+	if got := exitCode(err); got != exitTimeout {
+		t.Errorf("exitCode = %d, want %d", got, exitTimeout)
+	}
+}
+
+func TestRunLegsAtomic(t *testing.T) {
+	boom := errors.New("boom")
+	var calls []string
+	record := func(name, detail string, err error) func(context.Context) (string, error) {
+		return func(context.Context) (string, error) {
+			calls = append(calls, name)
+			return detail, err
+		}
+	}
+	undo := func(name string, err error) func(context.Context) error {
+		return func(context.Context) error {
+			calls = append(calls, "undo "+name)
+			return err
+		}
+	}
+
+	tests := []struct {
+		name      string
+		legs      []leg
+		wantCalls []string
+		want      []legStatus
+		exit      int
+	}{
+		{
+			// 取り消せない勤之助は最後に実行し、失敗したら Slack を取り消す
+			name: "stamp fails",
+			legs: []leg{
+				{target: "kinnosuke", run: record("stamp", "", boom)},
+				{target: "slack", run: record("react", "リアクション完了", nil), undo: undo("react", nil)},
+				{target: "slack-status", run: record("status", "ステータス設定", nil), undo: undo("status", nil)},
+			},
+			wantCalls: []string{"react", "status", "stamp", "undo status", "undo react"},
+			want:      []legStatus{legFailed, legRolledBack, legRolledBack},
+			exit:      exitError,
+		},
+		{
+			name: "slack fails before stamp",
+			legs: []leg{
+				{target: "kinnosuke", run: record("stamp", "出社 09:00", nil)},
+				{target: "slack", run: record("react", "リアクション完了", nil), undo: undo("react", nil)},
+				{target: "slack-status", run: record("status", "", boom), undo: undo("status", nil)},
+			},
+			wantCalls: []string{"react", "status", "undo react"},
+			want:      []legStatus{legSkipped, legRolledBack, legFailed},
+			exit:      exitError,
+		},
+		{
+			name: "undo fails",
+			legs: []leg{
+				{target: "kinnosuke", run: record("stamp", "", boom)},
+				{target: "slack", run: record("react", "リアクション完了", nil), undo: undo("react", errors.New("no permission"))},
+			},
+			wantCalls: []string{"react", "stamp", "undo react"},
+			want:      []legStatus{legFailed, legFailed},
+			exit:      exitError,
+		},
+		{
+			name: "skipped is not undone",
+			legs: []leg{
+				{target: "kinnosuke", run: record("stamp", "", boom)},
+				{target: "slack", run: record("react", "", &notify.SkipError{Reason: "リアクション済み"}), undo: undo("react", nil)},
+			},
+			wantCalls: []string{"react", "stamp"},
+			want:      []legStatus{legFailed, legSkipped},
+			exit:      exitError,
+		},
+		{
+			// 取り消せない通知は打刻の後に送り、失敗しても打刻・Slack は取り消さない
+			name: "best effort notifier fails after stamp",
+			legs: []leg{
+				{target: "webhook", run: record("webhook", "", boom), bestEffort: true},
+				{target: "kinnosuke", run: record("stamp", "出社 09:00", nil)},
+				{target: "slack", run: record("react", "リアクション完了", nil), undo: undo("react", nil)},
+			},
+			wantCalls: []string{"react", "stamp", "webhook"},
+			want:      []legStatus{legFailed, legOK, legOK},
+			exit:      exitPartialFailure,
+		},
+		{
+			name: "best effort notifier not sent when stamp fails",
+			legs: []leg{
+				{target: "webhook", run: record("webhook", "通知完了", nil), bestEffort: true},
+				{target: "kinnosuke", run: record("stamp", "", boom)},
+				{target: "slack", run: record("react", "リアクション完了", nil), undo: undo("react", nil)},
+			},
+			wantCalls: []string{"react", "stamp", "undo react"},
+			want:      []legStatus{legSkipped, legFailed, legRolledBack},
+			exit:      exitError,
+		},
+		{
+			name: "all ok",
+			legs: []leg{
+				{target: "kinnosuke", run: record("stamp", "出社 09:00", nil)},
+				{target: "slack", run: record("react", "リアクション完了", nil), undo: undo("react", nil)},
+			},
+			wantCalls: []string{"react", "stamp"},
+			want:      []legStatus{legOK, legOK},
+			exit:      exitOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = nil
+			results := runLegsAtomic(t.Context(), tt.legs)
+			if strings.Join(calls, ",") != strings.Join(tt.wantCalls, ",") {
+				t.Errorf("calls = %v, want %v", calls, tt.wantCalls)
+			}
+			for i, want := range tt.want {
+				if results[i].status != want {
+					t.Errorf("results[%d] = %+v, want status %d", i, results[i], want)
+				}
+			}
+			if got := exitCode(legsErr(results)); got != tt.exit {
+				t.Errorf("exitCode = %d, want %d", got, tt.exit)
+			}
+		})
+	}
+}
+
+func TestRunLegsAtomicUndoAfterCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	var undoErr error
+	results := runLegsAtomic(ctx, []leg{
+		{target: "kinnosuke", run: func(context.Context) (string, error) {
+			cancel() // Ctrl-C で打刻が中断された
+			return "", context.Canceled
+		}},
+		{
+			target: "slack",
+			run:    func(context.Context) (string, error) { return "リアクション完了", nil },
+			undo: func(ctx context.Context) error {
+				undoErr = ctx.Err()
+				return undoErr
+			},
+		},
+	})
+	// 取り消しは中断された context とは別の context で行う
+	if undoErr != nil || results[1].status != legRolledBack {
+		t.Errorf("undo ctx err = %v, results = %+v", undoErr, results)
+	}
+	if got := exitCode(legsErr(results)); got != exitInterrupted {
+		t.Errorf("exitCode = %d, want %d", got, exitInterrupted)
+	}
+}
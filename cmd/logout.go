@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"fmt"
+
+	"kintai/internal/kinnosuke"
+
+	"github.com/spf13/cobra"
+)
+
+var logoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "勤之助からログアウトし、保存しているセッションを削除する",
+	Long: `勤之助からログアウトし、ユーザーのキャッシュディレクトリに暗号化して保存している
+セッション（Cookie）を削除します。次回の打刻ではログインし直します。`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ok, err := kinnosuke.Logout(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("セッションの削除に失敗: %w", err)
+		}
+		if !ok {
+			fmt.Println("- 保存されたセッションはありません")
+			return nil
+		}
+		fmt.Println("✔ ログアウトし、保存されたセッションを削除しました")
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(logoutCmd)
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+
+	"kintai/internal/mode"
+
+	"github.com/spf13/cobra"
+)
+
+// modeRegistry は組み込みの mode に、プロファイルの slack.emoji.start と modes を重ねた一覧を作る。
+func modeRegistry() (*mode.Registry, error) {
+	modes := mode.Builtin()
+	for name, emoji := range activeProfile.Slack.Emoji.Start {
+		modes = append(modes, mode.Mode{Name: name, Emoji: emoji})
+	}
+	for name, mp := range activeProfile.Modes {
+		modes = append(modes, mode.Mode{
+			Name:        name,
+			Alias:       mp.Alias,
+			Description: mp.Description,
+			Emoji:       mp.Emoji,
+			StampType:   mp.StampType,
+			Note:        mp.Note,
+		})
+	}
+	return mode.NewRegistry(modes...)
+}
+
+// normalizeMode は --mode の値（名前または短縮名）から mode を引く。
+func normalizeMode(v string) (mode.Mode, error) {
+	reg, err := modeRegistry()
+	if err != nil {
+		return mode.Mode{}, err
+	}
+	m, ok := reg.Lookup(v)
+	if !ok {
+		return mode.Mode{}, fmt.Errorf("--mode(-m) must be one of %s", reg.Usage())
+	}
+	return m, nil
+}
+
+// completeMode は --mode のシェル補完候補を返す。
+func completeMode(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	// 補完時は PersistentPreRunE が走らないので、ここでプロファイルを読む
+	if err := loadProfile(); err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	reg, err := modeRegistry()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var out []string
+	for _, m := range reg.Modes() {
+		desc := m.Description
+		if m.Alias != "" {
+			desc = fmt.Sprintf("%s (%s)", desc, m.Alias)
+		}
+		out = append(out, m.Name+"\t"+desc)
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
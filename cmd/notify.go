@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"kintai/internal/auth"
+	"kintai/internal/config"
+	"kintai/internal/notify"
+	"kintai/internal/retry"
+)
+
+// notifierProfiles はプロファイルの通知先のうち、--only で実行するものを返す。未設定なら Slack のリアクションのみ。
+// only が slack なら Slack の通知先だけ、それ以外（空・notify）ならすべて。
+func notifierProfiles(only string) []config.NotifierProfile {
+	ps := activeProfile.Notifiers
+	if len(ps) == 0 {
+		ps = []config.NotifierProfile{{Type: notify.TypeSlack}}
+	}
+	if only != "slack" {
+		return ps
+	}
+	var slack []config.NotifierProfile
+	for _, p := range ps {
+		if p.Type == notify.TypeSlack {
+			slack = append(slack, p)
+		}
+	}
+	return slack
+}
+
+// notifyTarget は設定ファイルの通知先を notify.Target に変換する。
+// url_secret があれば URL を環境変数・シークレットストアから読む。
+func notifyTarget(p config.NotifierProfile) (notify.Target, error) {
+	t := notify.Target{
+		Type:      p.Type,
+		Name:      p.Name,
+		URL:       p.URL,
+		Command:   p.Command,
+		StartText: p.StartText,
+		EndText:   p.EndText,
+	}
+	if t.URL == "" && p.URLSecret != "" {
+		u, err := auth.LookupSecret(p.URLSecret)
+		if err != nil {
+			return notify.Target{}, err
+		}
+		t.URL = u
+	}
+	return t, nil
+}
+
+// validateNotifiers は only で実行する通知先の設定を検証する。Slack の通知先があれば Slack の設定も検証する。
+func validateNotifiers(only string) []error {
+	ps := notifierProfiles(only)
+	if len(ps) == 0 {
+		return []error{fmt.Errorf("%w: --only %s: no %s notifier in the profile", config.ErrMissing, only, only)}
+	}
+	var errs []error
+	seen := map[string]bool{}
+	for _, p := range ps {
+		t, err := notifyTarget(p)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := t.Validate(); err != nil {
+			errs = append(errs, flatten(err)...)
+			continue
+		}
+		name := notifierName(t)
+		if seen[name] {
+			errs = append(errs, fmt.Errorf("%w: notifier %s is duplicated (set name to tell them apart)", config.ErrInvalid, name))
+		}
+		seen[name] = true
+		if t.Type == notify.TypeSlack {
+			cfg, err := slackConfig()
+			if err != nil {
+				errs = append(errs, err)
+			} else {
+				errs = append(errs, flatten(cfg.Validate())...)
+			}
+		}
+	}
+	return errs
+}
+
+// notifierName は結果の表に出す通知先の名前。
+func notifierName(t notify.Target) string {
+	if t.Name != "" {
+		return t.Name
+	}
+	return t.Type
+}
+
+// notifierLegs は only で実行する通知先ごとに、thread（開始・終了）を send で通知する leg を返す。
+// force なら Slack はリアクション済みでもリアクションする。
+// 通知先の準備（URL の読み込み・Slack のクライアントの作成）に失敗しても、その通知先の leg の失敗になるだけで、
+// 勤之助の打刻や他の通知先は止めない。
+func notifierLegs(only string, force bool, thread string, send func(ctx context.Context, n notify.Notifier) error) []leg {
+	var legs []leg
+	for _, p := range notifierProfiles(only) {
+		t, err := notifyTarget(p)
+		if err != nil {
+			legs = append(legs, failedNotifyLeg(notifierName(notify.Target{Type: p.Type, Name: p.Name}), err))
+			continue
+		}
+		if t.Type == notify.TypeSlack {
+			legs = append(legs, slackNotifyLeg(t, force, thread, send))
+			continue
+		}
+		n, err := notify.New(t, notify.Options{Retry: retry.FromEnv()})
+		if err != nil {
+			legs = append(legs, failedNotifyLeg(notifierName(t), err))
+			continue
+		}
+		legs = append(legs, notifyLeg(n, thread, send))
+	}
+	return legs
+}
+
+// slackNotifyLeg は Slack の通知先の leg を返す。Slack のクライアントは leg の中で作るので、
+// Slack の設定やトークンの誤りはこの leg の失敗になる。取り消すときはリアクションを外す。
+func slackNotifyLeg(t notify.Target, force bool, thread string, send func(ctx context.Context, n notify.Notifier) error) leg {
+	var n notify.Notifier
+	return leg{
+		target: notifierName(t),
+		run: func(ctx context.Context) (string, error) {
+			sc, err := newSlackClient()
+			if err != nil {
+				return "", err
+			}
+			n = notify.NewSlack(t, sc, force)
+			return notifyLeg(n, thread, send).run(ctx)
+		},
+		undo: func(ctx context.Context) error {
+			return n.(notify.Reverter).Revert(ctx)
+		},
+	}
+}
+
+// failedNotifyLeg は準備に失敗した通知先の leg を返す。実行すると err で失敗する。
+// 取り消せない通知先と同じく、atomic でも打刻の後に実行し、失敗しても他を取り消さない。
+func failedNotifyLeg(target string, err error) leg {
+	return leg{
+		target:     target,
+		run:        func(context.Context) (string, error) { return "", err },
+		bestEffort: true,
+	}
+}
+
+// notifyLeg は n に通知する leg を返す。取り消せる通知先（Slack）なら、取り消すときは通知を取り消す。
+// 取り消せない通知先は bestEffort にして、atomic では打刻の後に送る。
+func notifyLeg(n notify.Notifier, thread string, send func(ctx context.Context, n notify.Notifier) error) leg {
+	l := leg{
+		target: n.Name(),
+		run: func(ctx context.Context) (string, error) {
+			if err := send(ctx, n); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("通知完了 (%s)", thread), nil
+		},
+	}
+	if r, ok := n.(notify.Reverter); ok {
+		l.undo = r.Revert
+	} else {
+		l.bestEffort = true
+	}
+	return l
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"kintai/internal/config"
+	"kintai/internal/mode"
+	"kintai/internal/notify"
+)
+
+// fakeNotifier は呼ばれた回数を数える Notifier。revertible なら Reverter も実装する。
+type fakeNotifier struct {
+	name     string
+	err      error
+	sent     int
+	reverted int
+}
+
+func (f *fakeNotifier) Name() string { return f.name }
+
+func (f *fakeNotifier) OnStart(context.Context, mode.Mode) error { f.sent++; return f.err }
+
+func (f *fakeNotifier) OnEnd(context.Context) error { f.sent++; return f.err }
+
+type revertibleNotifier struct{ *fakeNotifier }
+
+func (r revertibleNotifier) Revert(context.Context) error { r.reverted++; return nil }
+
+func TestNotifyLeg(t *testing.T) {
+	end := func(ctx context.Context, n notify.Notifier) error { return n.OnEnd(ctx) }
+	hook := &fakeNotifier{name: "hook"}
+	skipped := &fakeNotifier{name: "slack", err: &notify.SkipError{Reason: "リアクション済みのためスキップ"}}
+	slack := revertibleNotifier{&fakeNotifier{name: "slack"}}
+
+	results := runLegs(t.Context(), []leg{
+		notifyLeg(hook, "終了", end),
+		notifyLeg(skipped, "終了", end),
+	})
+	if r := results[0]; r.target != "hook" || r.status != legOK || r.detail != "通知完了 (終了)" {
+		t.Errorf("hook = %+v", r)
+	}
+	if r := results[1]; r.status != legSkipped || r.detail != "リアクション済みのためスキップ" || r.err != nil {
+		t.Errorf("skipped = %+v", r)
+	}
+
+	// 取り消せるのは Reverter を実装した通知先だけ
+	if l := notifyLeg(hook, "終了", end); l.undo != nil {
+		t.Error("hook leg should not be undoable")
+	}
+	boom := errors.New("boom")
+	results = runLegsAtomic(t.Context(), []leg{
+		{target: "kinnosuke", run: func(context.Context) (string, error) { return "", boom }},
+		notifyLeg(slack, "終了", end),
+	})
+	if slack.sent != 1 || slack.reverted != 1 || results[1].status != legRolledBack {
+		t.Errorf("slack sent=%d reverted=%d result=%+v, want sent, then reverted", slack.sent, slack.reverted, results[1])
+	}
+}
+
+func TestValidateNotifiers(t *testing.T) {
+	orig := activeProfile
+	t.Cleanup(func() { activeProfile = orig })
+	t.Setenv("KN_SECRET_STORE", "dotenv")
+	t.Setenv("KN_DISCORD_WEBHOOK", "https://discord.com/api/webhooks/1/abc")
+
+	activeProfile = config.Profile{Notifiers: []config.NotifierProfile{
+		{Type: "discord", URLSecret: "KN_DISCORD_WEBHOOK"},
+		{Type: "command", Command: []string{"true"}},
+	}}
+	if errs := validateNotifiers(""); len(errs) != 0 {
+		t.Errorf("validateNotifiers() = %v, want none", errs)
+	}
+
+	activeProfile = config.Profile{Notifiers: []config.NotifierProfile{
+		{Type: "teams"},
+		{Type: "command", Command: []string{"true"}},
+		{Type: "command", Command: []string{"false"}},
+	}}
+	errs := validateNotifiers("")
+	if len(errs) != 2 || !errors.Is(errs[0], config.ErrMissing) || !errors.Is(errs[1], config.ErrInvalid) {
+		t.Errorf("validateNotifiers() = %v, want missing url and duplicated command", errs)
+	}
+}
+
+func TestNotifierProfilesOnly(t *testing.T) {
+	orig := activeProfile
+	t.Cleanup(func() { activeProfile = orig })
+
+	activeProfile = config.Profile{Notifiers: []config.NotifierProfile{
+		{Type: "slack", Name: "slack-work"},
+		{Type: "command", Command: []string{"true"}},
+	}}
+	if ps := notifierProfiles("slack"); len(ps) != 1 || ps[0].Name != "slack-work" {
+		t.Errorf("notifierProfiles(slack) = %+v, want the slack notifier only", ps)
+	}
+	if ps := notifierProfiles("notify"); len(ps) != 2 {
+		t.Errorf("notifierProfiles(notify) = %+v, want all notifiers", ps)
+	}
+
+	// Slack の通知先が無いのに --only slack を指定したら設定の不足
+	activeProfile = config.Profile{Notifiers: []config.NotifierProfile{{Type: "command", Command: []string{"true"}}}}
+	if errs := validateNotifiers("slack"); len(errs) != 1 || !errors.Is(errs[0], config.ErrMissing) {
+		t.Errorf("validateNotifiers(slack) = %v, want missing slack notifier", errs)
+	}
+}
+
+func TestNotifierLegsSlackConfigError(t *testing.T) {
+	orig := activeProfile
+	t.Cleanup(func() { activeProfile = orig })
+	t.Setenv("KN_SECRET_STORE", "dotenv")
+	t.Setenv("SLACK_TOKEN", "")
+	t.Setenv("SLACK_CHANNEL", "")
+
+	activeProfile = config.Profile{Notifiers: []config.NotifierProfile{
+		{Type: "slack"},
+		{Type: "command", Command: []string{"true"}},
+	}}
+	end := func(ctx context.Context, n notify.Notifier) error { return n.OnEnd(ctx) }
+	legs := notifierLegs("", false, "終了", end)
+	if len(legs) != 2 {
+		t.Fatalf("legs = %d, want 2", len(legs))
+	}
+
+	// Slack の設定の誤りは Slack の leg の失敗になり、打刻や他の通知先は実行する
+	stamped := false
+	results := runLegs(t.Context(), append([]leg{stampLeg("退社", func(context.Context) (string, error) {
+		stamped = true
+		return "18:30", nil
+	})}, legs...))
+	if !stamped || results[0].status != legOK {
+		t.Errorf("kinnosuke = %+v, want stamped", results[0])
+	}
+	if r := results[1]; r.target != "slack" || r.status != legFailed || !errors.Is(r.err, config.ErrMissing) {
+		t.Errorf("slack = %+v, want failed with missing config", r)
+	}
+	if r := results[2]; r.status != legOK {
+		t.Errorf("command = %+v, want ok", r)
+	}
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"kintai/internal/kinnosuke"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	outOnly   string
+	outNote   string
+	outAtomic bool
+)
+
+var outCmd = &cobra.Command{
+	Use:   "out",
+	Short: "外出を打刻して、設定があればSlackのステータスを外出中にする",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// 勤怠ノ助：外出 / Slack：ステータスを外出中にする
+		return runOut(cmd.Context(), "外出", kinnosuke.StampOut, true)
+	},
+}
+
+var backCmd = &cobra.Command{
+	Use:   "back",
+	Short: "再入を打刻して、設定があればSlackの外出中ステータスを消す",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// 勤怠ノ助：再入 / Slack：外出中のステータスを消す
+		return runOut(cmd.Context(), "再入", kinnosuke.StampBack, false)
+	},
+}
+
+// runOut は外出・再入の打刻とステータスの変更を実行する。
+// setStatus なら外出中のステータスを設定し、そうでなければ消す。
+func runOut(ctx context.Context, label string, stamp func(context.Context, kinnosuke.StampOptions) (string, error), setStatus bool) error {
+	var legs []leg
+	if outOnly == "" || outOnly == "kinnosuke" {
+		legs = append(legs, stampLeg(label, func(ctx context.Context) (string, error) {
+			// 外出は1日に何度でもできるので、打刻済みでも打刻する
+			return stamp(ctx, kinnosuke.StampOptions{Note: outNote})
+		}))
+	}
+	if outOnly == "" || outOnly == "slack" {
+		cfg, err := slackConfig()
+		if err != nil {
+			return err
+		}
+		// 外出用のステータスを設定していない人の手動ステータスは消さない
+		legs = append(legs, statusLeg("外出", cfg.OutStatus, setStatus))
+	}
+	return runPipeline(ctx, os.Stdout, legs, outAtomic)
+}
+
+func init() {
+	// ネットワークに接続する前に設定をまとめて検証する
+	preRun := func(cmd *cobra.Command, args []string) error {
+		outOnly = normalizeOnly(outOnly)
+		if err := validateOnly(outOnly); err != nil {
+			return err
+		}
+		return validateConfig(outOnly)
+	}
+	for _, c := range []*cobra.Command{outCmd, backCmd} {
+		rootCmd.AddCommand(c)
+		c.Flags().StringVarP(&outOnly, "only", "o", "", "kinnosuke(kin)|slack(s) (省略時は両方実行)")
+		c.Flags().StringVarP(&outNote, "note", "n", "", "勤之助の打刻に付ける備考（行き先など）")
+		c.Flags().BoolVar(&outAtomic, "atomic", false, "Slack、勤之助の順に実行し、失敗したら済んだ Slack の操作を取り消す")
+		c.PreRunE = preRun
+	}
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"kintai/internal/kinnosuke"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	reportMonth  string
+	reportFormat string
+)
+
+// reportRow は月次勤怠1日分の出力形式。CSV の列と JSON のキーを兼ねる。
+type reportRow struct {
+	Date      string `json:"date"`
+	Weekday   string `json:"weekday"`
+	LeaveType string `json:"leave_type"`
+	Start     string `json:"start"`
+	Leave     string `json:"leave"`
+	Break     string `json:"break"`
+	Overtime  string `json:"overtime"`
+	Approval  string `json:"approval"`
+	Note      string `json:"note"`
+}
+
+func newReportRow(r kinnosuke.DayRecord) reportRow {
+	return reportRow{
+		Date:      r.Date.Format("2006-01-02"),
+		Weekday:   kinnosuke.WeekdayJa(r.Date.Weekday()),
+		LeaveType: r.LeaveType,
+		Start:     r.Start,
+		Leave:     r.Leave,
+		Break:     formatHM(r.Break),
+		Overtime:  formatHM(r.Overtime),
+		Approval:  r.Approval,
+		Note:      r.Note,
+	}
+}
+
+func (r reportRow) values() []string {
+	return []string{r.Date, r.Weekday, r.LeaveType, r.Start, r.Leave, r.Break, r.Overtime, r.Approval, r.Note}
+}
+
+var (
+	reportCSVHeader      = []string{"date", "weekday", "leave_type", "start", "leave", "break", "overtime", "approval", "note"}
+	reportMarkdownHeader = []string{"日付", "曜日", "休暇区分", "出社", "退社", "休憩", "残業", "承認状況", "備考"}
+)
+
+// formatHM は d を "1:30" 形式にする。0 なら空。
+func formatHM(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	m := int(d.Round(time.Minute) / time.Minute)
+	return fmt.Sprintf("%d:%02d", m/60, m%60)
+}
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "勤之助の月次勤怠を CSV / JSON / Markdown で出力する（打刻はしない）",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		month, err := parseMonth(reportMonth)
+		if err != nil {
+			return err
+		}
+		records, err := kinnosuke.Timesheet(cmd.Context(), month)
+		if err != nil {
+			return err
+		}
+		return writeReport(os.Stdout, reportFormat, records)
+	},
+}
+
+// parseMonth は "2026-10" 形式の年月を解釈する。空なら今月。
+func parseMonth(v string) (time.Time, error) {
+	loc, _ := time.LoadLocation("Asia/Tokyo")
+	if v == "" {
+		now := time.Now().In(loc)
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc), nil
+	}
+	t, err := time.ParseInLocation("2006-01", v, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("--month(-m) must be YYYY-MM: %q", v)
+	}
+	return t, nil
+}
+
+// writeReport は records を format（csv / json / markdown）で w に書く。
+func writeReport(w io.Writer, format string, records []kinnosuke.DayRecord) error {
+	rows := make([]reportRow, 0, len(records))
+	var overtime time.Duration
+	for _, r := range records {
+		rows = append(rows, newReportRow(r))
+		overtime += r.Overtime
+	}
+
+	switch format {
+	case "csv":
+		cw := csv.NewWriter(w)
+		if err := cw.Write(reportCSVHeader); err != nil {
+			return err
+		}
+		for _, r := range rows {
+			if err := cw.Write(r.values()); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rows)
+	case "markdown", "md":
+		var b strings.Builder
+		writeMarkdownRow(&b, reportMarkdownHeader)
+		b.WriteString("|" + strings.Repeat("---|", len(reportMarkdownHeader)) + "\n")
+		for _, r := range rows {
+			writeMarkdownRow(&b, r.values())
+		}
+		fmt.Fprintf(&b, "\n残業合計: %s\n", orZeroHM(formatHM(overtime)))
+		_, err := io.WriteString(w, b.String())
+		return err
+	default:
+		return fmt.Errorf("--format must be csv, json or markdown(md): %q", format)
+	}
+}
+
+func writeMarkdownRow(b *strings.Builder, cells []string) {
+	b.WriteString("|")
+	for _, c := range cells {
+		b.WriteString(" " + strings.ReplaceAll(c, "|", `\|`) + " |")
+	}
+	b.WriteString("\n")
+}
+
+func orZeroHM(s string) string {
+	if s == "" {
+		return "0:00"
+	}
+	return s
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+	reportCmd.Flags().StringVarP(&reportMonth, "month", "m", "", "対象の年月 YYYY-MM (省略時は今月)")
+	reportCmd.Flags().StringVar(&reportFormat, "format", "markdown", "csv|json|markdown(md)")
+	_ = reportCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(
+		[]string{"csv", "json", "markdown"}, cobra.ShellCompDirectiveNoFileComp))
+
+	reportCmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		if _, err := parseMonth(reportMonth); err != nil {
+			return err
+		}
+		switch reportFormat {
+		case "csv", "json", "markdown", "md":
+		default:
+			return fmt.Errorf("--format must be csv, json or markdown(md): %q", reportFormat)
+		}
+		return validateConfig("kinnosuke")
+	}
+}
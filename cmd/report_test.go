@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"kintai/internal/kinnosuke"
+)
+
+func TestWriteReport(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Tokyo")
+	records := []kinnosuke.DayRecord{
+		{Date: time.Date(2026, 10, 1, 0, 0, 0, 0, loc), Start: "09:00", Leave: "18:30", Break: time.Hour, Overtime: 30 * time.Minute, Approval: "承認済"},
+		{Date: time.Date(2026, 10, 2, 0, 0, 0, 0, loc), LeaveType: "有休", Note: "通院|午前"},
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"csv", "date,weekday,leave_type,start,leave,break,overtime,approval,note\n" +
+			"2026-10-01,木,,09:00,18:30,1:00,0:30,承認済,\n" +
+			"2026-10-02,金,有休,,,,,,通院|午前\n"},
+		{"json", `"date": "2026-10-01",`},
+		{"markdown", "| 2026-10-02 | 金 | 有休 |  |  |  |  |  | 通院\\|午前 |\n\n残業合計: 0:30\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var b strings.Builder
+			if err := writeReport(&b, tt.format, records); err != nil {
+				t.Fatalf("writeReport: %v", err)
+			}
+			if !strings.Contains(b.String(), tt.want) {
+				t.Errorf("output =\n%s\nwant to contain\n%s", b.String(), tt.want)
+			}
+		})
+	}
+
+	if err := writeReport(&strings.Builder{}, "xml", records); err == nil {
+		t.Error("writeReport(xml) must fail")
+	}
+}
+
+func TestParseMonth(t *testing.T) {
+	got, err := parseMonth("2026-10")
+	if err != nil || got.Year() != 2026 || got.Month() != time.October {
+		t.Errorf("parseMonth = %v, %v", got, err)
+	}
+	if _, err := parseMonth("2026/10"); err == nil {
+		t.Error("parseMonth(2026/10) must fail")
+	}
+}
@@ -1,24 +1,106 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"kintai/internal/config"
 
 	"github.com/spf13/cobra"
 )
 
+var (
+	configPath  string
+	profileName string
+	timeout     time.Duration
+
+	// cancelTimeout は --timeout で付けた期限を解放する。Execute の終わりに呼ぶ。
+	cancelTimeout context.CancelFunc = func() {}
+
+	// activeProfile は選択中のプロファイル。環境変数にならない設定（絵文字の対応など）はここから読む。
+	activeProfile config.Profile
+)
+
 var rootCmd = &cobra.Command{
 	Use:   "kn",
 	Short: "Kinnosuke + Slack kintai helper",
+	// エラーは Execute で1回だけ表示する（設定エラーの一覧が usage に埋もれないように）
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if timeout < 0 {
+			return fmt.Errorf("--timeout must not be negative")
+		}
+		if timeout > 0 {
+			var ctx context.Context
+			ctx, cancelTimeout = context.WithTimeout(cmd.Context(), timeout)
+			cmd.SetContext(ctx)
+		}
+		return loadProfile()
+	},
 }
 
+// Execute はルートコマンドを実行する。
+// Ctrl-C（SIGINT）・SIGTERM を受けたら context をキャンセルし、通信中のリクエストを中断して終了する。
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := rootCmd.ExecuteContext(ctx)
+	cancelTimeout()
+	stop()
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
+	}
+}
+
+// loadProfile は設定ファイルを読み込み、選択したプロファイルを環境変数に反映する。
+// 優先順位は フラグ > 環境変数（.env を含む）> プロファイル > デフォルト値。
+func loadProfile() error {
+	path := configPath
+	if path == "" {
+		path = os.Getenv("KN_CONFIG")
+	}
+	mustExist := path != ""
+	if path == "" {
+		p, err := config.DefaultPath()
+		if err != nil {
+			return nil // HOME が取れない環境では設定ファイルなしで動かす
+		}
+		path = p
+	}
+
+	f, err := config.Load(path, mustExist)
+	if err != nil {
+		return err
+	}
+
+	name := profileName
+	if name == "" {
+		name = os.Getenv("KN_PROFILE")
+	}
+	if name == "" {
+		name = f.DefaultProfile
+	}
+	p, err := f.Profile(name)
+	if err != nil {
+		return err
+	}
+	// シークレットストアがプロファイルごとに秘密情報を分けられるよう、選んだプロファイル名を伝える
+	if name != "" {
+		if err := os.Setenv("KN_PROFILE", name); err != nil {
+			return err
+		}
 	}
+	activeProfile = p
+	return p.ApplyEnv()
 }
 
 func init() {
-	// ここで global flags を増やすなら増やす
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "設定ファイルのパス (default: $XDG_CONFIG_HOME/kintai/config.toml)")
+	rootCmd.PersistentFlags().StringVarP(&profileName, "profile", "p", "", "使用するプロファイル名 (default: default_profile)")
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0, "コマンド全体のタイムアウト (例: 30s、1m。0 なら無制限)")
 }
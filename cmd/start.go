@@ -2,32 +2,21 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 	"os"
 
 	"kintai/internal/kinnosuke"
-	"kintai/internal/slackkintai"
+	"kintai/internal/notify"
 
 	"github.com/spf13/cobra"
 )
 
 var (
-	startMode string
-	startOnly string
+	startMode   string
+	startOnly   string
+	startForce  bool
+	startAtomic bool
 )
 
-// normalizeMode は --mode の短縮値を正規化する
-func normalizeMode(v string) string {
-	switch v {
-	case "o":
-		return "office"
-	case "r":
-		return "remote"
-	default:
-		return v
-	}
-}
-
 // normalizeOnly は --only の短縮値を正規化する
 func normalizeOnly(v string) string {
 	switch v {
@@ -35,6 +24,8 @@ func normalizeOnly(v string) string {
 		return "kinnosuke"
 	case "s":
 		return "slack"
+	case "n":
+		return "notify"
 	default:
 		return v
 	}
@@ -43,57 +34,46 @@ func normalizeOnly(v string) string {
 var startCmd = &cobra.Command{
 	Use:     "start",
 	Aliases: []string{"s"},
-	Short:   "出社打刻して、Slackの業務開始スレにリアクションする",
+	Short:   "出社打刻して、Slackの業務開始スレへのリアクションなどで通知する",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		startMode = normalizeMode(startMode)
-		if startMode != "office" && startMode != "remote" {
-			return fmt.Errorf("--mode(-m) must be office(o) or remote(r)")
+		m, err := normalizeMode(startMode)
+		if err != nil {
+			return err
 		}
 
-		// --only バリデーション
-		if startOnly != "" && startOnly != "kinnosuke" && startOnly != "slack" {
-			return fmt.Errorf("--only(-o) must be kinnosuke(kin) or slack(s)")
-		}
-
-		// 勤怠ノ助：出社
-		if startOnly == "" || startOnly == "kinnosuke" {
-			t, err := kinnosuke.StampStart()
-			if err != nil {
-				return err
-			}
-			fmt.Printf("✔ 出社完了 (%s)\n", t)
-		}
-
-		// Slack：開始スレにリアクション
-		if startOnly == "" || startOnly == "slack" {
-			if err := slackkintai.ReactStart(context.Background(), startMode); err != nil {
-				return err
-			}
-			fmt.Println("✔ Slackリアクション完了 (開始)")
-		}
-
-		return nil
+		// 勤怠ノ助の出社打刻と、通知先（Slack の開始スレへのリアクションなど）への通知を並行に実行する
+		return stampAndNotify{
+			only:   startOnly,
+			force:  startForce,
+			atomic: startAtomic,
+			label:  "出社",
+			thread: "開始",
+			stamp: func(ctx context.Context, opts kinnosuke.StampOptions) (string, error) {
+				opts.Type, opts.Note = m.StampType, m.Note
+				return kinnosuke.StampStart(ctx, opts)
+			},
+			send: func(ctx context.Context, n notify.Notifier) error {
+				return n.OnStart(ctx, m)
+			},
+		}.run(cmd.Context(), os.Stdout)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(startCmd)
-	startCmd.Flags().StringVarP(&startMode, "mode", "m", "", "office(o)|remote(r) (required)")
-	startCmd.Flags().StringVarP(&startOnly, "only", "o", "", "kinnosuke(kin)|slack(s) (省略時は両方実行)")
+	startCmd.Flags().StringVarP(&startMode, "mode", "m", "", "office(o)|remote(r)|設定ファイルで追加した mode (required)")
+	startCmd.Flags().StringVarP(&startOnly, "only", "o", "", "kinnosuke(kin)|slack(s)|notify(n)（slack は Slack の通知先だけ、notify は通知先すべて） (省略時は両方実行)")
+	startCmd.Flags().BoolVarP(&startForce, "force", "f", false, "打刻済み・リアクション済みでも実行する")
+	startCmd.Flags().BoolVar(&startAtomic, "atomic", false, "取り消せる通知（Slack）、勤之助、その他の通知の順に実行し、打刻までに失敗したら済んだ Slack の操作を取り消す")
 	_ = startCmd.MarkFlagRequired("mode")
+	_ = startCmd.RegisterFlagCompletionFunc("mode", completeMode)
 
-	// envの未設定を早めに気づけるように（任意）
+	// ネットワークに接続する前に設定をまとめて検証する
 	startCmd.PreRunE = func(cmd *cobra.Command, args []string) error {
 		startOnly = normalizeOnly(startOnly)
-		if startOnly == "" || startOnly == "kinnosuke" {
-			_ = os.Getenv("KIN_COMPANYCD")
-			_ = os.Getenv("KIN_LOGINCD")
-			_ = os.Getenv("KIN_PASSWORD")
-		}
-		if startOnly == "" || startOnly == "slack" {
-			_ = os.Getenv("SLACK_TOKEN")
-			_ = os.Getenv("SLACK_CHANNEL")
+		if err := validateNotifyOnly(startOnly); err != nil {
+			return err
 		}
-		return nil
+		return validateNotifyConfig(startOnly)
 	}
 }
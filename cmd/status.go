@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"kintai/internal/kinnosuke"
+	"kintai/internal/slackkintai"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	statusOnly string
+	statusJSON bool
+)
+
+// kinnosukeStatus は status --json の勤之助部分。
+type kinnosukeStatus struct {
+	UserName  string `json:"user_name"`
+	StartTime string `json:"start_time"`
+	LeaveTime string `json:"leave_time"`
+}
+
+type statusReport struct {
+	Kinnosuke *kinnosukeStatus    `json:"kinnosuke,omitempty"`
+	Slack     *slackkintai.Status `json:"slack,omitempty"`
+}
+
+var statusCmd = &cobra.Command{
+	Use:     "status",
+	Aliases: []string{"st"},
+	Short:   "本日の勤之助の打刻状況とSlackリアクション状況を表示する",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var rep statusReport
+
+		if statusOnly == "" || statusOnly == "kinnosuke" {
+			page, err := kinnosuke.Today(cmd.Context())
+			if err != nil {
+				return err
+			}
+			rep.Kinnosuke = &kinnosukeStatus{
+				UserName:  page.UserName,
+				StartTime: page.StartTime,
+				LeaveTime: page.LeaveTime,
+			}
+		}
+
+		if statusOnly == "" || statusOnly == "slack" {
+			sc, err := newSlackClient()
+			if err != nil {
+				return err
+			}
+			st, err := sc.Status(cmd.Context())
+			if err != nil {
+				return err
+			}
+			rep.Slack = &st
+		}
+
+		if statusJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(rep)
+		}
+		printStatus(rep)
+		return nil
+	},
+}
+
+func printStatus(rep statusReport) {
+	if k := rep.Kinnosuke; k != nil {
+		fmt.Printf("勤之助: 出社 %s / 退社 %s\n", orUnstamped(k.StartTime), orUnstamped(k.LeaveTime))
+	}
+	if s := rep.Slack; s != nil {
+		fmt.Printf("Slack:  開始スレ %s / 終了スレ %s\n", threadLabel(s.Start), threadLabel(s.End))
+	}
+}
+
+func orUnstamped(t string) string {
+	if t == "" {
+		return "未打刻"
+	}
+	return t
+}
+
+func threadLabel(t slackkintai.ThreadStatus) string {
+	switch {
+	case !t.Found:
+		return "リマインダーなし"
+	case !t.Reacted:
+		return "未リアクション"
+	default:
+		return "リアクション済み (:" + strings.Join(t.Emojis, ": :") + ":)"
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+	statusCmd.Flags().StringVarP(&statusOnly, "only", "o", "", "kinnosuke(kin)|slack(s) (省略時は両方表示)")
+	statusCmd.Flags().BoolVar(&statusJSON, "json", false, "JSONで出力する")
+
+	statusCmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		statusOnly = normalizeOnly(statusOnly)
+		if err := validateOnly(statusOnly); err != nil {
+			return err
+		}
+		return validateConfig(statusOnly)
+	}
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"kintai/internal/config"
+	"kintai/internal/kinnosuke"
+	"kintai/internal/slackkintai"
+)
+
+// configError は設定検証で見つかった問題をすべてまとめたエラー。
+type configError struct {
+	errs []error
+}
+
+func (e *configError) Error() string {
+	var b strings.Builder
+	b.WriteString("設定に問題があります:")
+	for _, err := range e.errs {
+		b.WriteString("\n  - ")
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+func (e *configError) Unwrap() []error { return e.errs }
+
+// validateConfig は only で実行対象になる連携先の設定を、ネットワークに接続する前にまとめて検証する。
+func validateConfig(only string) error {
+	var errs []error
+	if only == "" || only == "kinnosuke" {
+		errs = append(errs, flatten(kinnosuke.ValidateEnv())...)
+	}
+	if only == "" || only == "slack" {
+		cfg, err := slackConfig()
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			errs = append(errs, flatten(cfg.Validate())...)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return &configError{errs: errs}
+}
+
+// validateNotifyConfig は start / end 用の validateConfig。Slack の代わりに、only で実行する通知先を検証する。
+func validateNotifyConfig(only string) error {
+	var errs []error
+	if only == "" || only == "kinnosuke" {
+		errs = append(errs, flatten(kinnosuke.ValidateEnv())...)
+	}
+	if only != "kinnosuke" {
+		errs = append(errs, validateNotifiers(only)...)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return &configError{errs: errs}
+}
+
+// flatten は errors.Join で束ねられたエラーを1件ずつに展開する。
+func flatten(err error) []error {
+	if err == nil {
+		return nil
+	}
+	if j, ok := err.(interface{ Unwrap() []error }); ok {
+		return j.Unwrap()
+	}
+	return []error{err}
+}
+
+// slackConfig は環境変数（トークン・チャンネル）とプロファイル（リマインダー・絵文字）から Slack の設定を作る。
+func slackConfig() (slackkintai.Config, error) {
+	cfg, err := slackkintai.ConfigFromEnv()
+	if err != nil {
+		return slackkintai.Config{}, err
+	}
+	sp := activeProfile.Slack
+	cfg.StartReminder = slackkintai.Reminder{Match: sp.Reminders.Start.Match, Text: sp.Reminders.Start.Text}
+	cfg.EndReminder = slackkintai.Reminder{Match: sp.Reminders.End.Match, Text: sp.Reminders.End.Text}
+	cfg.EndEmoji = sp.Emoji.End
+	cfg.BreakStartEmoji = sp.Emoji.BreakStart
+	cfg.BreakEndEmoji = sp.Emoji.BreakEnd
+	cfg.BreakStatus = slackStatus(sp.Status["break"])
+	cfg.OutStatus = slackStatus(sp.Status["out"])
+
+	reg, err := modeRegistry()
+	if err != nil {
+		return slackkintai.Config{}, err
+	}
+	cfg.StartEmojis = reg.Emojis()
+	return cfg, nil
+}
+
+// slackStatus は設定ファイルのステータスを slackkintai.UserStatus に変換する。
+func slackStatus(p config.StatusProfile) slackkintai.UserStatus {
+	return slackkintai.UserStatus{
+		Text:       p.Text,
+		Emoji:      p.Emoji,
+		Expiration: time.Duration(p.ExpireMinutes) * time.Minute,
+	}
+}
+
+// newSlackClient は slackConfig の設定で Slack クライアントを作る。
+func newSlackClient() (*slackkintai.Client, error) {
+	cfg, err := slackConfig()
+	if err != nil {
+		return nil, err
+	}
+	return slackkintai.New(cfg)
+}
+
+// validateOnly は --only の値を検証する。
+func validateOnly(only string) error {
+	if only != "" && only != "kinnosuke" && only != "slack" {
+		return errors.New("--only(-o) must be kinnosuke(kin) or slack(s)")
+	}
+	return nil
+}
+
+// validateNotifyOnly は start / end の --only の値を検証する。notify（通知先すべて）も使える。
+func validateNotifyOnly(only string) error {
+	if only != "" && only != "kinnosuke" && only != "slack" && only != "notify" {
+		return errors.New("--only(-o) must be kinnosuke(kin), slack(s) or notify(n)")
+	}
+	return nil
+}
@@ -2,11 +2,21 @@ module kintai
 
 go 1.25.4
 
+require (
+	filippo.io/age v1.2.1
+	github.com/BurntSushi/toml v1.5.0
+	github.com/godbus/dbus/v5 v5.1.0
+	github.com/joho/godotenv v1.5.1
+	github.com/slack-go/slack v0.17.3
+	github.com/spf13/cobra v1.10.2
+	golang.org/x/net v0.47.0
+	golang.org/x/term v0.37.0
+)
+
 require (
 	github.com/gorilla/websocket v1.5.3 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
-	github.com/joho/godotenv v1.5.1 // indirect
-	github.com/slack-go/slack v0.17.3 // indirect
-	github.com/spf13/cobra v1.10.2 // indirect
 	github.com/spf13/pflag v1.0.9 // indirect
+	golang.org/x/crypto v0.44.0 // indirect
+	golang.org/x/sys v0.38.0 // indirect
 )
@@ -0,0 +1,104 @@
+package audit
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"kintai/internal/kinnosuke"
+)
+
+// ErrIssuesFound は打刻漏れが見つかったことを表す。
+// cron などから終了コードで検知できるように、見つかったときはこれを返す。
+var ErrIssuesFound = errors.New("missing stamps found")
+
+// Kind は打刻漏れの種類。
+type Kind string
+
+const (
+	// MissingLeave は出社はあるが退社がない。
+	MissingLeave Kind = "missing_leave"
+	// MissingStart は退社はあるが出社がない。
+	MissingStart Kind = "missing_start"
+	// NoStamps は出勤日なのに打刻が1つもない。
+	NoStamps Kind = "no_stamps"
+)
+
+// approved は休暇の申請が承認済みとみなす承認状況。
+const approved = "承認済"
+
+// Issue は打刻漏れ1件。
+type Issue struct {
+	Date time.Time
+	Kind Kind
+	// Record は元になった月次勤怠の1日分。
+	Record kinnosuke.DayRecord
+}
+
+// String は "10/05(月) 退社なし（出社 09:00）" の形式で返す。
+func (i Issue) String() string {
+	day := fmt.Sprintf("%s(%s)", i.Date.Format("01/02"), kinnosuke.WeekdayJa(i.Date.Weekday()))
+	var s string
+	switch i.Kind {
+	case MissingLeave:
+		s = fmt.Sprintf("%s 退社なし（出社 %s）", day, i.Record.Start)
+	case MissingStart:
+		s = fmt.Sprintf("%s 出社なし（退社 %s）", day, i.Record.Leave)
+	default:
+		s = day + " 打刻なし"
+	}
+	if i.Record.LeaveType != "" {
+		s += fmt.Sprintf(" ※%s: %s", i.Record.LeaveType, orDash(i.Record.Approval))
+	}
+	return s
+}
+
+// Check は records のうち before より前の出勤日から打刻漏れを探す。
+// 所定休日と、承認済みの全休（DayRecord.FullDayLeave）は対象外。
+// 半休・時間休や、取得単位が読み取れない休暇区分の日は出勤日として扱う。
+func Check(records []kinnosuke.DayRecord, before time.Time) []Issue {
+	var issues []Issue
+	for _, r := range records {
+		if !r.Date.Before(before) || r.Holiday {
+			continue
+		}
+		if r.FullDayLeave() && r.Approval == approved {
+			continue
+		}
+
+		var kind Kind
+		switch {
+		case r.Start != "" && r.Leave == "":
+			kind = MissingLeave
+		case r.Start == "" && r.Leave != "":
+			kind = MissingStart
+		case r.Start == "" && r.Leave == "":
+			kind = NoStamps
+		default:
+			continue
+		}
+		issues = append(issues, Issue{Date: r.Date, Kind: kind, Record: r})
+	}
+	return issues
+}
+
+// Message は issues を Slack の DM などにそのまま貼れる文面にする。
+func Message(month time.Time, issues []Issue) string {
+	if len(issues) == 0 {
+		return fmt.Sprintf("✔ %s の打刻漏れはありません", month.Format("2006-01"))
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "⚠ %s の打刻漏れ %d件", month.Format("2006-01"), len(issues))
+	for _, i := range issues {
+		b.WriteString("\n- " + i.String())
+	}
+	return b.String()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
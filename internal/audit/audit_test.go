@@ -0,0 +1,68 @@
+package audit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"kintai/internal/kinnosuke"
+)
+
+func TestCheck(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Tokyo")
+	day := func(d int) time.Time { return time.Date(2026, time.October, d, 0, 0, 0, 0, loc) }
+	records := []kinnosuke.DayRecord{
+		{Date: day(1), Start: "09:00", Leave: "18:30"},
+		{Date: day(2), Start: "09:00"},
+		{Date: day(3), Holiday: true},
+		{Date: day(5), Leave: "18:00"},
+		{Date: day(6)},
+		{Date: day(7), LeaveType: "有休", Approval: "承認済"},
+		{Date: day(8), LeaveType: "有休", Approval: "申請中"},
+		{Date: day(9), LeaveType: "午前半休", Approval: "承認済"},
+		{Date: day(10), LeaveType: "午前休", Approval: "承認済"}, // "半休" を含まなくても半休
+		{Date: day(11), LeaveType: "特別休暇", Approval: "承認済"},
+		{Date: day(12), LeaveType: "時間休", Approval: "承認済"},
+		{Date: day(13), Start: "09:00"}, // 当日以降は対象外
+	}
+
+	got := Check(records, day(13))
+	want := []struct {
+		day  int
+		kind Kind
+	}{
+		{2, MissingLeave},
+		{5, MissingStart},
+		{6, NoStamps},
+		{8, NoStamps},
+		{9, NoStamps},
+		{10, NoStamps},
+		{12, NoStamps},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Check = %v, want %d issues", got, len(want))
+	}
+	for i, w := range want {
+		if got[i].Date.Day() != w.day || got[i].Kind != w.kind {
+			t.Errorf("issue[%d] = %s %s, want day %d %s", i, got[i].Date.Format("01/02"), got[i].Kind, w.day, w.kind)
+		}
+	}
+}
+
+func TestMessage(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Tokyo")
+	month := time.Date(2026, time.October, 1, 0, 0, 0, 0, loc)
+	issues := []Issue{
+		{Date: time.Date(2026, 10, 5, 0, 0, 0, 0, loc), Kind: MissingLeave, Record: kinnosuke.DayRecord{Start: "09:00"}},
+		{Date: time.Date(2026, 10, 8, 0, 0, 0, 0, loc), Kind: NoStamps, Record: kinnosuke.DayRecord{LeaveType: "有休", Approval: "申請中"}},
+	}
+
+	got := Message(month, issues)
+	want := "⚠ 2026-10 の打刻漏れ 2件\n- 10/05(月) 退社なし（出社 09:00）\n- 10/08(木) 打刻なし ※有休: 申請中"
+	if got != want {
+		t.Errorf("Message =\n%s\nwant\n%s", got, want)
+	}
+	if !strings.HasPrefix(Message(month, nil), "✔") {
+		t.Errorf("Message(nil) = %q", Message(month, nil))
+	}
+}
@@ -2,21 +2,37 @@ package auth
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
+
+	"github.com/joho/godotenv"
+
+	"kintai/internal/config"
 )
 
+// EnvEntry は .env ファイルの1行分のキーと値。
+type EnvEntry struct {
+	Key   string
+	Value string
+}
+
 // UpsertEnvToken は .env ファイルの指定キーを上書き（なければ追加）する。
+// 値はダブルクォートで囲み、godotenv で読み戻すと同じ値になるようにエスケープする。
 // 既存の内容（コメント・空行・順序）はそのまま保持する。
 // ファイルが存在しない場合は新規作成する（パーミッション 0600）。
 func UpsertEnvToken(path, key, value string) error {
+	quoted, err := quoteEnvValue(value)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", config.ErrInvalid, key, err)
+	}
 	lines, err := readLines(path)
 	if err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf(".envの読み込みに失敗: %w", err)
 	}
 
-	newLine := fmt.Sprintf(`%s="%s"`, key, value)
+	newLine := key + "=" + quoted
 	found := false
 	prefix := key + "="
 
@@ -40,6 +56,80 @@ func UpsertEnvToken(path, key, value string) error {
 	return writeLines(path, lines)
 }
 
+// RemoveEnvKey は .env ファイルから指定キーの行を削除する。
+// 他の行（コメント・空行・順序）はそのまま保持する。ファイルやキーが無ければ何もしない。
+func RemoveEnvKey(path, key string) error {
+	lines, err := readLines(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf(".envの読み込みに失敗: %w", err)
+	}
+
+	prefix := key + "="
+	kept := lines[:0]
+	removed := false
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), prefix) {
+			removed = true
+			continue
+		}
+		kept = append(kept, line)
+	}
+	if !removed {
+		return nil
+	}
+	return writeLines(path, kept)
+}
+
+// envValueEscaper はダブルクォートの値の中で godotenv が解釈する文字をエスケープする
+// （そのままだと $ は変数展開、" は値の終わり、改行は次の行として読まれる）。
+var envValueEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	`$`, `\$`,
+	"\n", `\n`,
+	"\r", `\r`,
+)
+
+// quoteEnvValue は value をダブルクォートで囲んだ .env の値にする。
+// 末尾が \ の値は、エスケープしても godotenv が閉じのクォートを \" と読んでしまうため受け付けない。
+func quoteEnvValue(value string) (string, error) {
+	if strings.HasSuffix(value, `\`) {
+		return "", errors.New(`.env に保存する値の末尾に \ は使えません`)
+	}
+	return `"` + envValueEscaper.Replace(value) + `"`, nil
+}
+
+// ListEnv は .env ファイルのキーと値をファイル内の順序で返す。コメント・空行は除く。
+// ファイルが存在しない場合は空を返す。
+func ListEnv(path string) ([]EnvEntry, error) {
+	lines, err := readLines(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf(".envの読み込みに失敗: %w", err)
+	}
+
+	var entries []EnvEntry
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		kv, err := godotenv.Unmarshal(trimmed)
+		if err != nil {
+			return nil, fmt.Errorf(".envの解析に失敗 (%q): %w", trimmed, err)
+		}
+		for k, v := range kv {
+			entries = append(entries, EnvEntry{Key: k, Value: v})
+		}
+	}
+	return entries, nil
+}
+
 func readLines(path string) ([]string, error) {
 	f, err := os.Open(path)
 	if err != nil {
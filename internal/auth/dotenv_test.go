@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/joho/godotenv"
+
+	"kintai/internal/config"
+)
+
+func TestDotenvUpsertListRemove(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	orig := "# 勤之助\nKIN_COMPANYCD=\"C001\"\nKIN_LOGINCD=taro\n"
+	if err := os.WriteFile(path, []byte(orig), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := UpsertEnvToken(path, "KIN_LOGINCD", "hanako"); err != nil {
+		t.Fatal(err)
+	}
+	if err := UpsertEnvToken(path, "SLACK_CHANNEL", "C01ABCDEF23"); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ListEnv(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []EnvEntry{
+		{Key: "KIN_COMPANYCD", Value: "C001"},
+		{Key: "KIN_LOGINCD", Value: "hanako"},
+		{Key: "SLACK_CHANNEL", Value: "C01ABCDEF23"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListEnv = %+v, want %+v", got, want)
+	}
+
+	if err := RemoveEnvKey(path, "SLACK_CHANNEL"); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 削除した行以外（UpsertEnvToken が挟んだ空行も含む）はそのまま残す
+	if want := "# 勤之助\nKIN_COMPANYCD=\"C001\"\nKIN_LOGINCD=\"hanako\"\n\n"; string(b) != want {
+		t.Errorf(".env =\n%s\nwant\n%s", b, want)
+	}
+}
+
+func TestRemoveEnvKeyKeepsBlankLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	orig := "A=1\n\nB=2\n\n\n"
+	if err := os.WriteFile(path, []byte(orig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := RemoveEnvKey(path, "B"); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "A=1\n\n\n\n"; string(b) != want {
+		t.Errorf(".env = %q, want %q", b, want)
+	}
+}
+
+func TestUpsertEnvTokenRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	values := map[string]string{
+		"QUOTE":     `p"a"ss`,
+		"DOLLAR":    "$HOME",
+		"BRACE":     "${HOME}x",
+		"BACKSLASH": `a\nb\$c\"d`,
+		"NEWLINE":   "line1\nline2\r\n",
+		"HASH":      "a #b",
+		"SPACES":    "  a  ",
+		"SINGLE":    "it's",
+	}
+	for k, v := range values {
+		if err := UpsertEnvToken(path, k, v); err != nil {
+			t.Fatalf("UpsertEnvToken(%s): %v", k, err)
+		}
+	}
+
+	got, err := godotenv.Read(path)
+	if err != nil {
+		t.Fatalf("godotenv.Read: %v", err)
+	}
+	if !reflect.DeepEqual(got, values) {
+		t.Errorf("godotenv.Read =\n%q\nwant\n%q", got, values)
+	}
+}
+
+func TestUpsertEnvTokenTrailingBackslash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	// 読み戻せない値は書かない
+	if err := UpsertEnvToken(path, "X", `abc\`); !errors.Is(err, config.ErrInvalid) {
+		t.Errorf("UpsertEnvToken err = %v, want ErrInvalid", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf(".env was written: %v", err)
+	}
+}
+
+func TestListEnvMissingFile(t *testing.T) {
+	got, err := ListEnv(filepath.Join(t.TempDir(), "none"))
+	if err != nil || got != nil {
+		t.Errorf("ListEnv = %v, %v; want nil, nil", got, err)
+	}
+	if err := RemoveEnvKey(filepath.Join(t.TempDir(), "none"), "X"); err != nil {
+		t.Errorf("RemoveEnvKey on missing file: %v", err)
+	}
+}
@@ -23,7 +23,7 @@ import (
 const (
 	listenAddr  = "localhost:9876"
 	redirectURI = "https://localhost:9876/callback"
-	userScopes  = "reactions:write,channels:history,channels:read"
+	userScopes  = "reactions:write,channels:history,channels:read,users.profile:write,chat:write"
 	timeout     = 2 * time.Minute
 )
 
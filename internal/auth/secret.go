@@ -0,0 +1,138 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"kintai/internal/config"
+)
+
+// ErrSecretNotFound はシークレットストアに指定のキーが無いことを表す。
+var ErrSecretNotFound = errors.New("secret not found")
+
+// シークレットストアのバックエンド名（KN_SECRET_STORE の値）。
+const (
+	BackendAuto    = "auto"    // Secret Service が使えればそれ、なければ .env
+	BackendKeyring = "keyring" // freedesktop Secret Service（GNOME Keyring / KWallet など）
+	BackendFile    = "file"    // age のパスフレーズ暗号化ファイル
+	BackendDotenv  = "dotenv"  // 従来どおり .env に平文で保存
+)
+
+// SecretStore はトークンやパスワードなどの秘密情報の保存先。
+type SecretStore interface {
+	// Name は表示用のバックエンド名を返す。
+	Name() string
+	// Get は key の値を返す。無ければ ErrSecretNotFound。
+	Get(key string) (string, error)
+	// Set は key の値を保存（上書き）する。
+	Set(key, value string) error
+	// Delete は key を削除する。無くてもエラーにしない。
+	Delete(key string) error
+}
+
+// SecretStoreOptions は OpenSecretStore の設定。ゼロ値のフィールドはデフォルト値で補われる。
+type SecretStoreOptions struct {
+	// Backend は BackendAuto / BackendKeyring / BackendFile / BackendDotenv のいずれか。
+	Backend string
+	// Namespace はプロファイルごとに秘密情報を分けるための名前（keyring・file で使用）。
+	Namespace string
+	// FilePath は file バックエンドの暗号化ファイルのパス。
+	FilePath string
+	// Passphrase は file バックエンドのパスフレーズを返す関数。
+	Passphrase func() (string, error)
+	// EnvPath は dotenv バックエンドの .env のパス。
+	EnvPath string
+}
+
+// OpenSecretStore は opts に従ってシークレットストアを開く。
+func OpenSecretStore(opts SecretStoreOptions) (SecretStore, error) {
+	if opts.Namespace == "" {
+		opts.Namespace = "default"
+	}
+	if opts.EnvPath == "" {
+		opts.EnvPath = ".env"
+	}
+
+	switch opts.Backend {
+	case "", BackendAuto:
+		if os.Getenv("DBUS_SESSION_BUS_ADDRESS") != "" {
+			if s, err := openSecretService(opts.Namespace); err == nil {
+				return s, nil
+			}
+		}
+		return &dotenvStore{path: opts.EnvPath}, nil
+	case BackendKeyring:
+		s, err := openSecretService(opts.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	case BackendFile:
+		if opts.FilePath == "" {
+			p, err := defaultSecretFilePath()
+			if err != nil {
+				return nil, err
+			}
+			opts.FilePath = p
+		}
+		if opts.Passphrase == nil {
+			opts.Passphrase = passphraseFromEnvOrPrompt
+		}
+		return &fileStore{path: opts.FilePath, namespace: opts.Namespace, passphrase: opts.Passphrase}, nil
+	case BackendDotenv:
+		return &dotenvStore{path: opts.EnvPath}, nil
+	default:
+		return nil, fmt.Errorf("%w: KN_SECRET_STORE must be auto, keyring, file or dotenv (got %q)", config.ErrInvalid, opts.Backend)
+	}
+}
+
+// Plaintext は s が秘密情報を平文で保存するストア（dotenv）かを返す。
+func Plaintext(s SecretStore) bool {
+	_, ok := s.(*dotenvStore)
+	return ok
+}
+
+// SecretStoreFromEnv は環境変数（KN_SECRET_STORE / KN_SECRET_FILE / KN_PROFILE）に従ってシークレットストアを開く。
+func SecretStoreFromEnv() (SecretStore, error) {
+	return OpenSecretStore(SecretStoreOptions{
+		Backend:   strings.TrimSpace(os.Getenv("KN_SECRET_STORE")),
+		Namespace: strings.TrimSpace(os.Getenv("KN_PROFILE")),
+		FilePath:  strings.TrimSpace(os.Getenv("KN_SECRET_FILE")),
+	})
+}
+
+// LookupSecret は環境変数 key を返し、空ならシークレットストアから読む。
+// どちらにも無ければ空文字を返す（エラーにはしない）。
+func LookupSecret(key string) (string, error) {
+	if v := strings.TrimSpace(os.Getenv(key)); v != "" {
+		return v, nil
+	}
+	s, err := SecretStoreFromEnv()
+	if err != nil {
+		return "", err
+	}
+	v, err := s.Get(key)
+	if errors.Is(err, ErrSecretNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s から %s を読み込めません: %w", s.Name(), key, err)
+	}
+	return v, nil
+}
+
+// defaultSecretFilePath は $XDG_CONFIG_HOME/kintai/secrets.age を返す。
+func defaultSecretFilePath() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "kintai", "secrets.age"), nil
+}
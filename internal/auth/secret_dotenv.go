@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"errors"
+	"os"
+
+	"github.com/joho/godotenv"
+)
+
+// dotenvStore は .env に平文で保存する従来方式のストア。
+type dotenvStore struct {
+	path string
+}
+
+func (s *dotenvStore) Name() string { return ".env (" + s.path + ")" }
+
+func (s *dotenvStore) Get(key string) (string, error) {
+	env, err := godotenv.Read(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrSecretNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	v, ok := env[key]
+	if !ok || v == "" {
+		return "", ErrSecretNotFound
+	}
+	return v, nil
+}
+
+func (s *dotenvStore) Set(key, value string) error {
+	return UpsertEnvToken(s.path, key, value)
+}
+
+func (s *dotenvStore) Delete(key string) error {
+	return RemoveEnvKey(s.path, key)
+}
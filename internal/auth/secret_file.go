@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"filippo.io/age"
+	"golang.org/x/term"
+
+	"kintai/internal/config"
+)
+
+// fileStore は age（scrypt パスフレーズ）で暗号化したファイルに保存するストア。
+// 中身は {namespace: {key: value}} の JSON。
+type fileStore struct {
+	path       string
+	namespace  string
+	passphrase func() (string, error)
+	// workFactor は scrypt の work factor（log2）。0 なら age のデフォルト。
+	workFactor int
+
+	pass string // 1プロセス内で何度も聞かないようにキャッシュする
+}
+
+func (s *fileStore) Name() string { return "暗号化ファイル (" + s.path + ")" }
+
+func (s *fileStore) Get(key string) (string, error) {
+	all, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	v, ok := all[s.namespace][key]
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+	return v, nil
+}
+
+func (s *fileStore) Set(key, value string) error {
+	all, err := s.load()
+	if err != nil {
+		return err
+	}
+	if all[s.namespace] == nil {
+		all[s.namespace] = map[string]string{}
+	}
+	all[s.namespace][key] = value
+	return s.save(all)
+}
+
+func (s *fileStore) Delete(key string) error {
+	all, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := all[s.namespace][key]; !ok {
+		return nil
+	}
+	delete(all[s.namespace], key)
+	return s.save(all)
+}
+
+func (s *fileStore) getPassphrase() (string, error) {
+	if s.pass != "" {
+		return s.pass, nil
+	}
+	p, err := s.passphrase()
+	if err != nil {
+		return "", err
+	}
+	if p == "" {
+		return "", fmt.Errorf("%w: パスフレーズが空です", config.ErrMissing)
+	}
+	s.pass = p
+	return p, nil
+}
+
+func (s *fileStore) load() (map[string]map[string]string, error) {
+	all := map[string]map[string]string{}
+
+	f, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return all, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	pass, err := s.getPassphrase()
+	if err != nil {
+		return nil, err
+	}
+	id, err := age.NewScryptIdentity(pass)
+	if err != nil {
+		return nil, err
+	}
+	r, err := age.Decrypt(f, id)
+	if err != nil {
+		return nil, fmt.Errorf("暗号化ファイルの復号に失敗（パスフレーズを確認してください）: %w", err)
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &all); err != nil {
+		return nil, fmt.Errorf("暗号化ファイルの形式が正しくありません: %w", err)
+	}
+	return all, nil
+}
+
+func (s *fileStore) save(all map[string]map[string]string) error {
+	pass, err := s.getPassphrase()
+	if err != nil {
+		return err
+	}
+	rcpt, err := age.NewScryptRecipient(pass)
+	if err != nil {
+		return err
+	}
+	if s.workFactor > 0 {
+		rcpt.SetWorkFactor(s.workFactor)
+	}
+	plain, err := json.Marshal(all)
+	if err != nil {
+		return err
+	}
+	return WriteEncryptedFile(s.path, plain, rcpt)
+}
+
+// WriteEncryptedFile は plain を rcpt で age 暗号化し、path に 0600 で書き出す（親ディレクトリは 0700 で作る）。
+// 書き込み途中で壊れないよう一時ファイル経由で置き換える。
+func WriteEncryptedFile(path string, plain []byte, rcpt age.Recipient) error {
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, rcpt)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(plain); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// passphraseFromEnvOrPrompt は KN_SECRET_PASSPHRASE を返し、未設定なら端末で入力を求める。
+func passphraseFromEnvOrPrompt() (string, error) {
+	if p := os.Getenv("KN_SECRET_PASSPHRASE"); p != "" {
+		return p, nil
+	}
+	p, err := PromptSecret("シークレットファイルのパスフレーズ")
+	if errors.Is(err, errNotTerminal) {
+		return "", fmt.Errorf("%w: KN_SECRET_PASSPHRASE（暗号化ファイルのパスフレーズ）", config.ErrMissing)
+	}
+	return p, err
+}
+
+var errNotTerminal = errors.New("stdin is not a terminal")
+
+// PromptSecret は端末でエコーなしに秘密情報の入力を求める。
+func PromptSecret(label string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", errNotTerminal
+	}
+	fmt.Fprintf(os.Stderr, "%s: ", label)
+	b, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,212 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// freedesktop Secret Service API（https://specifications.freedesktop.org/secret-service/）
+const (
+	ssDest          = "org.freedesktop.secrets"
+	ssPath          = dbus.ObjectPath("/org/freedesktop/secrets")
+	ssService       = "org.freedesktop.Secret.Service"
+	ssCollection    = "org.freedesktop.Secret.Collection"
+	ssItem          = "org.freedesktop.Secret.Item"
+	ssPrompt        = "org.freedesktop.Secret.Prompt"
+	ssNoPrompt      = dbus.ObjectPath("/")
+	ssAppAttribute  = "kintai"
+	ssDefaultAlias  = "default"
+	ssTextPlainType = "text/plain; charset=utf8"
+)
+
+// ssSecret は Secret Service の Secret 構造体 (oayays)。
+type ssSecret struct {
+	Session     dbus.ObjectPath
+	Parameters  []byte
+	Value       []byte
+	ContentType string
+}
+
+// secretServiceStore は Secret Service（GNOME Keyring / KWallet など）に保存するストア。
+// 項目は application=kintai, profile=<namespace>, key=<key> の属性で識別する。
+type secretServiceStore struct {
+	conn      *dbus.Conn
+	session   dbus.ObjectPath
+	namespace string
+}
+
+func openSecretService(namespace string) (*secretServiceStore, error) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("D-Bus セッションバスに接続できません: %w", err)
+	}
+	return newSecretService(conn, namespace)
+}
+
+// newSecretService は conn 上の Secret Service とセッションを開く。
+func newSecretService(conn *dbus.Conn, namespace string) (*secretServiceStore, error) {
+	var (
+		out     dbus.Variant
+		session dbus.ObjectPath
+	)
+	err := conn.Object(ssDest, ssPath).
+		Call(ssService+".OpenSession", 0, "plain", dbus.MakeVariant("")).
+		Store(&out, &session)
+	if err != nil {
+		return nil, fmt.Errorf("secret service が使えません: %w", err)
+	}
+	return &secretServiceStore{conn: conn, session: session, namespace: namespace}, nil
+}
+
+func (s *secretServiceStore) Name() string { return "OSキーリング (Secret Service)" }
+
+func (s *secretServiceStore) Get(key string) (string, error) {
+	items, err := s.search(key)
+	if err != nil {
+		return "", err
+	}
+	if len(items) == 0 {
+		return "", ErrSecretNotFound
+	}
+
+	var sec ssSecret
+	err = s.conn.Object(ssDest, items[0]).
+		Call(ssItem+".GetSecret", 0, s.session).
+		Store(&sec)
+	if err != nil {
+		return "", fmt.Errorf("GetSecret failed: %w", err)
+	}
+	return string(sec.Value), nil
+}
+
+func (s *secretServiceStore) Set(key, value string) error {
+	var collection dbus.ObjectPath
+	err := s.conn.Object(ssDest, ssPath).
+		Call(ssService+".ReadAlias", 0, ssDefaultAlias).
+		Store(&collection)
+	if err != nil {
+		return fmt.Errorf("ReadAlias failed: %w", err)
+	}
+	if collection == ssNoPrompt {
+		return errors.New("secret service にデフォルトのキーリングがありません")
+	}
+	if err := s.unlock([]dbus.ObjectPath{collection}); err != nil {
+		return err
+	}
+
+	props := map[string]dbus.Variant{
+		ssItem + ".Label":      dbus.MakeVariant(fmt.Sprintf("kintai: %s (%s)", key, s.namespace)),
+		ssItem + ".Attributes": dbus.MakeVariant(s.attributes(key)),
+	}
+	sec := ssSecret{Session: s.session, Value: []byte(value), ContentType: ssTextPlainType}
+
+	var item, prompt dbus.ObjectPath
+	err = s.conn.Object(ssDest, collection).
+		Call(ssCollection+".CreateItem", 0, props, sec, true).
+		Store(&item, &prompt)
+	if err != nil {
+		return fmt.Errorf("CreateItem failed: %w", err)
+	}
+	return s.prompt(prompt)
+}
+
+func (s *secretServiceStore) Delete(key string) error {
+	items, err := s.search(key)
+	if err != nil {
+		return err
+	}
+	for _, it := range items {
+		var prompt dbus.ObjectPath
+		if err := s.conn.Object(ssDest, it).Call(ssItem+".Delete", 0).Store(&prompt); err != nil {
+			return fmt.Errorf("Delete failed: %w", err)
+		}
+		if err := s.prompt(prompt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *secretServiceStore) attributes(key string) map[string]string {
+	return map[string]string{
+		"application": ssAppAttribute,
+		"profile":     s.namespace,
+		"key":         key,
+	}
+}
+
+// search は key の項目を探し、ロックされていれば解除して返す。
+func (s *secretServiceStore) search(key string) ([]dbus.ObjectPath, error) {
+	var unlocked, locked []dbus.ObjectPath
+	err := s.conn.Object(ssDest, ssPath).
+		Call(ssService+".SearchItems", 0, s.attributes(key)).
+		Store(&unlocked, &locked)
+	if err != nil {
+		return nil, fmt.Errorf("SearchItems failed: %w", err)
+	}
+	if len(locked) > 0 {
+		if err := s.unlock(locked); err != nil {
+			return nil, err
+		}
+	}
+	return append(unlocked, locked...), nil
+}
+
+func (s *secretServiceStore) unlock(paths []dbus.ObjectPath) error {
+	var (
+		unlocked []dbus.ObjectPath
+		prompt   dbus.ObjectPath
+	)
+	err := s.conn.Object(ssDest, ssPath).
+		Call(ssService+".Unlock", 0, paths).
+		Store(&unlocked, &prompt)
+	if err != nil {
+		return fmt.Errorf("Unlock failed: %w", err)
+	}
+	return s.prompt(prompt)
+}
+
+// prompt はキーリングの解除ダイアログなどが必要なときに表示し、完了を待つ。
+func (s *secretServiceStore) prompt(path dbus.ObjectPath) error {
+	if path == "" || path == ssNoPrompt {
+		return nil
+	}
+
+	if err := s.conn.AddMatchSignal(
+		dbus.WithMatchObjectPath(path),
+		dbus.WithMatchInterface(ssPrompt),
+		dbus.WithMatchMember("Completed"),
+	); err != nil {
+		return err
+	}
+	defer s.conn.RemoveMatchSignal( //nolint:errcheck
+		dbus.WithMatchObjectPath(path),
+		dbus.WithMatchInterface(ssPrompt),
+		dbus.WithMatchMember("Completed"),
+	)
+
+	ch := make(chan *dbus.Signal, 1)
+	s.conn.Signal(ch)
+	defer s.conn.RemoveSignal(ch)
+
+	if err := s.conn.Object(ssDest, path).Call(ssPrompt+".Prompt", 0, "").Err; err != nil {
+		return fmt.Errorf("Prompt failed: %w", err)
+	}
+
+	for sig := range ch {
+		if sig.Path != path || sig.Name != ssPrompt+".Completed" {
+			continue
+		}
+		// Completed は (dismissed bool, result variant) を送ってくる
+		if len(sig.Body) < 2 {
+			return fmt.Errorf("secret service の Prompt.Completed の形式が不正です（%d 個の値）", len(sig.Body))
+		}
+		if dismissed, _ := sig.Body[0].(bool); dismissed {
+			return errors.New("キーリングの解除がキャンセルされました")
+		}
+		return nil
+	}
+	return errors.New("secret service との接続が切れました")
+}
@@ -0,0 +1,206 @@
+package auth
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const testBusConfig = `<!DOCTYPE busconfig PUBLIC "-//freedesktop//DTD D-BUS Bus Configuration 1.0//EN"
+ "http://www.freedesktop.org/standards/dbus/1.0/busconfig.dtd">
+<busconfig>
+  <type>session</type>
+  <listen>unix:path=%s</listen>
+  <auth>EXTERNAL</auth>
+  <policy context="default">
+    <allow send_destination="*" eavesdrop="true"/>
+    <allow eavesdrop="true"/>
+    <allow own="*"/>
+  </policy>
+</busconfig>
+`
+
+// startTestBus は一時的な dbus-daemon を起動してアドレスを返す。dbus-daemon が無ければ skip する。
+func startTestBus(t *testing.T) string {
+	t.Helper()
+	bin, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon not found")
+	}
+
+	dir := t.TempDir()
+	conf := filepath.Join(dir, "bus.conf")
+	if err := os.WriteFile(conf, []byte(fmt.Sprintf(testBusConfig, filepath.Join(dir, "bus"))), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(bin, "--config-file="+conf, "--nofork", "--print-address")
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Skipf("dbus-daemon could not start: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+
+	addr, err := bufio.NewReader(out).ReadString('\n')
+	if err != nil {
+		t.Fatalf("read dbus address: %v", err)
+	}
+	return strings.TrimSpace(addr)
+}
+
+// fakeSecretService は Secret Service の最小限の偽実装（プロンプトなし・常にアンロック）。
+type fakeSecretService struct {
+	conn *dbus.Conn
+
+	mu    sync.Mutex
+	items map[dbus.ObjectPath]*fakeItem
+	next  int
+}
+
+type fakeItem struct {
+	svc   *fakeSecretService
+	path  dbus.ObjectPath
+	attrs map[string]string
+	value []byte
+}
+
+type fakeCollection struct{ svc *fakeSecretService }
+
+const fakeCollectionPath = dbus.ObjectPath("/org/freedesktop/secrets/collection/login")
+
+func (s *fakeSecretService) OpenSession(alg string, in dbus.Variant) (dbus.Variant, dbus.ObjectPath, *dbus.Error) {
+	if alg != "plain" {
+		return dbus.Variant{}, "", dbus.MakeFailedError(fmt.Errorf("unsupported algorithm %s", alg))
+	}
+	return dbus.MakeVariant(""), "/org/freedesktop/secrets/session/1", nil
+}
+
+func (s *fakeSecretService) SearchItems(attrs map[string]string) ([]dbus.ObjectPath, []dbus.ObjectPath, *dbus.Error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var found []dbus.ObjectPath
+	for p, it := range s.items {
+		if maps.Equal(it.attrs, attrs) {
+			found = append(found, p)
+		}
+	}
+	return found, []dbus.ObjectPath{}, nil
+}
+
+func (s *fakeSecretService) Unlock(objs []dbus.ObjectPath) ([]dbus.ObjectPath, dbus.ObjectPath, *dbus.Error) {
+	return objs, ssNoPrompt, nil
+}
+
+func (s *fakeSecretService) ReadAlias(name string) (dbus.ObjectPath, *dbus.Error) {
+	if name == ssDefaultAlias {
+		return fakeCollectionPath, nil
+	}
+	return ssNoPrompt, nil
+}
+
+func (c *fakeCollection) CreateItem(props map[string]dbus.Variant, secret ssSecret, replace bool) (dbus.ObjectPath, dbus.ObjectPath, *dbus.Error) {
+	s := c.svc
+	attrs, ok := props[ssItem+".Attributes"].Value().(map[string]string)
+	if !ok {
+		return "", "", dbus.MakeFailedError(fmt.Errorf("bad attributes"))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if replace {
+		for _, it := range s.items {
+			if maps.Equal(it.attrs, attrs) {
+				it.value = secret.Value
+				return it.path, ssNoPrompt, nil
+			}
+		}
+	}
+	s.next++
+	it := &fakeItem{
+		svc:   s,
+		path:  dbus.ObjectPath(fmt.Sprintf("%s/%d", fakeCollectionPath, s.next)),
+		attrs: attrs,
+		value: secret.Value,
+	}
+	s.items[it.path] = it
+	if err := s.conn.Export(it, it.path, ssItem); err != nil {
+		return "", "", dbus.MakeFailedError(err)
+	}
+	return it.path, ssNoPrompt, nil
+}
+
+func (it *fakeItem) GetSecret(session dbus.ObjectPath) (ssSecret, *dbus.Error) {
+	it.svc.mu.Lock()
+	defer it.svc.mu.Unlock()
+	return ssSecret{Session: session, Value: it.value, ContentType: ssTextPlainType}, nil
+}
+
+func (it *fakeItem) Delete() (dbus.ObjectPath, *dbus.Error) {
+	it.svc.mu.Lock()
+	defer it.svc.mu.Unlock()
+	delete(it.svc.items, it.path)
+	_ = it.svc.conn.Export(nil, it.path, ssItem)
+	return ssNoPrompt, nil
+}
+
+func connectTestBus(t *testing.T, addr string) *dbus.Conn {
+	t.Helper()
+	conn, err := dbus.Connect(addr)
+	if err != nil {
+		t.Fatalf("connect %s: %v", addr, err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func TestSecretServiceStore(t *testing.T) {
+	addr := startTestBus(t)
+
+	provider := connectTestBus(t, addr)
+	svc := &fakeSecretService{conn: provider, items: map[dbus.ObjectPath]*fakeItem{}}
+	if err := provider.Export(svc, ssPath, ssService); err != nil {
+		t.Fatal(err)
+	}
+	if err := provider.Export(&fakeCollection{svc: svc}, fakeCollectionPath, ssCollection); err != nil {
+		t.Fatal(err)
+	}
+	if reply, err := provider.RequestName(ssDest, dbus.NameFlagDoNotQueue); err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+		t.Fatalf("RequestName = %v, %v", reply, err)
+	}
+
+	client := connectTestBus(t, addr)
+	work, err := newSecretService(client, "work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStoreRoundTrip(t, work)
+
+	if err := work.Set("KIN_PASSWORD", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	side, err := newSecretService(client, "side-company")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := side.Get("KIN_PASSWORD"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("other namespace: err = %v, want ErrSecretNotFound", err)
+	}
+	if got, err := work.Get("KIN_PASSWORD"); err != nil || got != "secret" {
+		t.Errorf("Get = %q, %v; want secret", got, err)
+	}
+}
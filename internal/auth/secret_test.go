@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"kintai/internal/config"
+)
+
+// testStoreRoundTrip は SecretStore の基本操作（保存・取得・上書き・削除）を確認する。
+func testStoreRoundTrip(t *testing.T, s SecretStore) {
+	t.Helper()
+
+	if _, err := s.Get("SLACK_TOKEN"); !errors.Is(err, ErrSecretNotFound) {
+		t.Fatalf("Get before Set: err = %v, want ErrSecretNotFound", err)
+	}
+	if err := s.Set("SLACK_TOKEN", "xoxp-1"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := s.Set("SLACK_TOKEN", "xoxp-2"); err != nil {
+		t.Fatalf("Set (overwrite): %v", err)
+	}
+	if got, err := s.Get("SLACK_TOKEN"); err != nil || got != "xoxp-2" {
+		t.Fatalf("Get = %q, %v; want xoxp-2", got, err)
+	}
+	if err := s.Delete("SLACK_TOKEN"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.Get("SLACK_TOKEN"); !errors.Is(err, ErrSecretNotFound) {
+		t.Fatalf("Get after Delete: err = %v, want ErrSecretNotFound", err)
+	}
+	if err := s.Delete("SLACK_TOKEN"); err != nil {
+		t.Fatalf("Delete (missing): %v", err)
+	}
+}
+
+func TestDotenvStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("# comment\nKIN_LOGINCD=\"taro\"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	testStoreRoundTrip(t, &dotenvStore{path: path})
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "# comment\nKIN_LOGINCD=\"taro\"") {
+		t.Errorf(".env lost existing lines:\n%s", b)
+	}
+}
+
+func TestDotenvStoreSpecialChars(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	s := &dotenvStore{path: path}
+
+	// .env の構文として意味を持つ文字も、そのまま読み戻せる
+	const pass = `p"a$HOME`
+	if err := s.Set("KIN_PASSWORD", pass); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := s.Set("SLACK_TOKEN", "xoxp-1"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if got, err := s.Get("KIN_PASSWORD"); err != nil || got != pass {
+		t.Errorf("Get(KIN_PASSWORD) = %q, %v; want %q", got, err, pass)
+	}
+	if got, err := s.Get("SLACK_TOKEN"); err != nil || got != "xoxp-1" {
+		t.Errorf("Get(SLACK_TOKEN) = %q, %v; want xoxp-1", got, err)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kintai", "secrets.age")
+	pass := func() (string, error) { return "correct horse", nil }
+	const wf = 10 // テストを速くするため scrypt を軽くする
+
+	testStoreRoundTrip(t, &fileStore{path: path, namespace: "work", passphrase: pass, workFactor: wf})
+
+	work := &fileStore{path: path, namespace: "work", passphrase: pass, workFactor: wf}
+	if err := work.Set("KIN_PASSWORD", "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "secret") {
+		t.Error("secret is stored in plaintext")
+	}
+	if fi, err := os.Stat(path); err != nil || fi.Mode().Perm() != 0600 {
+		t.Errorf("file mode = %v, %v; want 0600", fi.Mode().Perm(), err)
+	}
+
+	// プロファイルごとに分かれている
+	side := &fileStore{path: path, namespace: "side-company", passphrase: pass}
+	if _, err := side.Get("KIN_PASSWORD"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("other namespace: err = %v, want ErrSecretNotFound", err)
+	}
+
+	wrong := &fileStore{path: path, namespace: "work", passphrase: func() (string, error) { return "wrong", nil }}
+	if _, err := wrong.Get("KIN_PASSWORD"); err == nil {
+		t.Error("wrong passphrase should fail")
+	}
+}
+
+func TestOpenSecretStoreInvalidBackend(t *testing.T) {
+	if _, err := OpenSecretStore(SecretStoreOptions{Backend: "vault"}); !errors.Is(err, config.ErrInvalid) {
+		t.Fatalf("err = %v, want config.ErrInvalid", err)
+	}
+}
+
+func TestLookupSecretPrefersEnv(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("KN_SECRET_STORE", BackendDotenv)
+	if err := os.WriteFile(".env", []byte(`KIN_PASSWORD="from-store"`+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("KIN_PASSWORD", "from-env")
+	if got, err := LookupSecret("KIN_PASSWORD"); err != nil || got != "from-env" {
+		t.Errorf("LookupSecret = %q, %v; want from-env", got, err)
+	}
+
+	t.Setenv("KIN_PASSWORD", "")
+	if got, err := LookupSecret("KIN_PASSWORD"); err != nil || got != "from-store" {
+		t.Errorf("LookupSecret = %q, %v; want from-store", got, err)
+	}
+
+	t.Setenv("SLACK_TOKEN", "")
+	if got, err := LookupSecret("SLACK_TOKEN"); err != nil || got != "" {
+		t.Errorf("LookupSecret(missing) = %q, %v; want empty", got, err)
+	}
+}
@@ -0,0 +1,240 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/BurntSushi/toml"
+)
+
+var (
+	// ErrProfileNotFound は指定したプロファイルが設定ファイルに無いことを表す。
+	ErrProfileNotFound = errors.New("profile not found")
+	// ErrMissing は必要な設定（環境変数・設定ファイルの項目）が足りないことを表す。
+	// 各パッケージは「%w: KIN_COMPANYCD」のように足りない項目名を添えて wrap する。
+	ErrMissing = errors.New("missing config")
+	// ErrInvalid は設定の形式が正しくないことを表す。ErrMissing と同様に wrap して使う。
+	ErrInvalid = errors.New("invalid config")
+)
+
+// File は設定ファイル（config.toml）全体。
+type File struct {
+	// DefaultProfile は --profile / KN_PROFILE を指定しなかったときに使うプロファイル名。
+	DefaultProfile string             `toml:"default_profile"`
+	Profiles       map[string]Profile `toml:"profiles"`
+
+	path string
+}
+
+// Profile は1つの勤務先（会社・ワークスペース）分の設定。
+type Profile struct {
+	// SecretStore はトークン・パスワードの保存先（auto / keyring / file / dotenv）。
+	SecretStore string `toml:"secret_store"`
+	// SecretFile は secret_store = "file" のときの暗号化ファイルのパス。
+	SecretFile string `toml:"secret_file"`
+
+	Kinnosuke KinnosukeProfile `toml:"kinnosuke"`
+	Slack     SlackProfile     `toml:"slack"`
+	// Modes は mode 名 → 定義。組み込みの office / remote を上書き・追加する。
+	Modes map[string]ModeProfile `toml:"modes"`
+	// Overtime は kn balance の残業時間の警告。
+	Overtime OvertimeProfile `toml:"overtime"`
+	// Notifiers は kn start / kn end で出社・退社を知らせる通知先。省略時は Slack のリアクションのみ。
+	Notifiers []NotifierProfile `toml:"notifiers"`
+}
+
+// NotifierProfile は通知先1つ分の設定。
+type NotifierProfile struct {
+	// Type は slack / webhook / teams / discord / command のいずれか。
+	Type string `toml:"type"`
+	// Name は結果の表に出す名前（省略時は Type）。
+	Name string `toml:"name"`
+	// URL は webhook / teams / discord の送信先。
+	URL string `toml:"url"`
+	// URLSecret は URL の代わりに、環境変数・シークレットストアから URL を読むときのキー名。
+	URLSecret string `toml:"url_secret"`
+	// Command は command で実行するコマンドと引数（シェルは介さない）。
+	Command []string `toml:"command"`
+	// StartText / EndText は送る文言。{mode} は mode の説明に置き換える。
+	StartText string `toml:"start_text"`
+	EndText   string `toml:"end_text"`
+}
+
+// OvertimeProfile は残業時間の警告の設定。
+type OvertimeProfile struct {
+	// WarnHours は月の残業時間がこの時間以上になったら警告する（36協定の月45時間など）。0 なら警告しない。
+	WarnHours float64 `toml:"warn_hours"`
+}
+
+// ModeProfile は出社種別（mode）1つ分の定義。
+type ModeProfile struct {
+	// Alias は --mode に指定できる短縮名。
+	Alias string `toml:"alias"`
+	// Description は補完候補に出す説明。
+	Description string `toml:"description"`
+	// Emoji は Slack の開始スレに付ける絵文字名。
+	Emoji string `toml:"emoji"`
+	// StampType は勤之助の打刻種別（timerecorder_stamping_type）。省略時は出社（1）。
+	StampType string `toml:"stamp_type"`
+	// Note は勤之助の打刻に添える備考。
+	Note string `toml:"note"`
+}
+
+// KinnosukeProfile は勤之助の設定。
+type KinnosukeProfile struct {
+	CompanyCD string `toml:"company_cd"`
+	LoginCD   string `toml:"login_cd"`
+	Password  string `toml:"password"`
+	BaseURL   string `toml:"base_url"`
+	// SkipOnLeave は kn start / kn end の前に休暇申請一覧を確認し、承認済みの全休ならスキップするか。
+	// 確認のために勤之助へのアクセスが増えるので、既定では確認しない。
+	SkipOnLeave bool `toml:"skip_on_leave"`
+}
+
+// SlackProfile は Slack の設定。
+type SlackProfile struct {
+	Token        string `toml:"token"`
+	Channel      string `toml:"channel"`
+	ClientID     string `toml:"client_id"`
+	ClientSecret string `toml:"client_secret"`
+
+	Reminders RemindersProfile `toml:"reminders"`
+	Emoji     EmojiProfile     `toml:"emoji"`
+	// Status はイベント名（"break" / "out"）→ その間に設定する Slack ステータス。
+	Status map[string]StatusProfile `toml:"status"`
+}
+
+// RemindersProfile は開始スレ・終了スレのリマインダーを探す条件。
+type RemindersProfile struct {
+	Start ReminderProfile `toml:"start"`
+	End   ReminderProfile `toml:"end"`
+}
+
+// ReminderProfile はリマインダー1つ分の照合条件。
+type ReminderProfile struct {
+	// Match は exact / prefix / regex のいずれか（省略時は exact）。
+	Match string `toml:"match"`
+	Text  string `toml:"text"`
+}
+
+// EmojiProfile はリアクションに使う絵文字名（コロンなし）。
+type EmojiProfile struct {
+	// Start は mode → 開始スレの絵文字。
+	Start map[string]string `toml:"start"`
+	// End は終了スレの絵文字。
+	End string `toml:"end"`
+	// BreakStart / BreakEnd は休憩入り・休憩戻りで開始スレに付ける絵文字（省略時はリアクションしない）。
+	BreakStart string `toml:"break_start"`
+	BreakEnd   string `toml:"break_end"`
+}
+
+// StatusProfile は Slack のステータス1つ分。
+type StatusProfile struct {
+	Text  string `toml:"text"`
+	Emoji string `toml:"emoji"`
+	// ExpireMinutes は自動で消えるまでの分数（0 なら消えない）。
+	ExpireMinutes int `toml:"expire_minutes"`
+}
+
+// DefaultPath は $XDG_CONFIG_HOME/kintai/config.toml（未設定なら ~/.config/kintai/config.toml）を返す。
+func DefaultPath() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "kintai", "config.toml"), nil
+}
+
+// Load は path の設定ファイルを読み込む。
+// ファイルが存在しない場合、mustExist が false なら空の File を返す。
+func Load(path string, mustExist bool) (*File, error) {
+	f := &File{path: path}
+	if _, err := toml.DecodeFile(path, f); err != nil {
+		if errors.Is(err, os.ErrNotExist) && !mustExist {
+			return f, nil
+		}
+		return nil, fmt.Errorf("設定ファイルの読み込みに失敗 (%s): %w", path, err)
+	}
+	return f, nil
+}
+
+// Path は読み込んだ設定ファイルのパスを返す。
+func (f *File) Path() string { return f.path }
+
+// Profile は name のプロファイルを返す。
+// name が空なら DefaultProfile を使い、それも空ならゼロ値の Profile を返す。
+func (f *File) Profile(name string) (Profile, error) {
+	if name == "" {
+		name = f.DefaultProfile
+	}
+	if name == "" {
+		return Profile{}, nil
+	}
+	p, ok := f.Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("%w: %q (%s, available: %v)", ErrProfileNotFound, name, f.path, f.ProfileNames())
+	}
+	return p, nil
+}
+
+// ProfileNames はプロファイル名を昇順で返す。
+func (f *File) ProfileNames() []string {
+	names := make([]string, 0, len(f.Profiles))
+	for n := range f.Profiles {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Env はプロファイルの値を対応する環境変数名で返す。空の値は含めない。
+func (p Profile) Env() map[string]string {
+	env := map[string]string{}
+	for k, v := range map[string]string{
+		"KIN_COMPANYCD":       p.Kinnosuke.CompanyCD,
+		"KIN_LOGINCD":         p.Kinnosuke.LoginCD,
+		"KIN_PASSWORD":        p.Kinnosuke.Password,
+		"KIN_BASE_URL":        p.Kinnosuke.BaseURL,
+		"SLACK_TOKEN":         p.Slack.Token,
+		"SLACK_CHANNEL":       p.Slack.Channel,
+		"SLACK_CLIENT_ID":     p.Slack.ClientID,
+		"SLACK_CLIENT_SECRET": p.Slack.ClientSecret,
+		"KN_SECRET_STORE":     p.SecretStore,
+		"KN_SECRET_FILE":      p.SecretFile,
+		"KIN_SKIP_ON_LEAVE":   onOff(p.Kinnosuke.SkipOnLeave),
+	} {
+		if v != "" {
+			env[k] = v
+		}
+	}
+	return env
+}
+
+// onOff は true なら "on"、false なら空（環境変数に反映しない）を返す。
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return ""
+}
+
+// ApplyEnv はプロファイルの値を、まだ設定されていない環境変数にだけ反映する。
+// 優先順位を「環境変数（.env を含む）> プロファイル」にするため、既存の値は上書きしない。
+func (p Profile) ApplyEnv() error {
+	for k, v := range p.Env() {
+		if os.Getenv(k) != "" {
+			continue
+		}
+		if err := os.Setenv(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
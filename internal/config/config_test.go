@@ -0,0 +1,131 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const sample = `
+default_profile = "work"
+
+[profiles.work.kinnosuke]
+company_cd = "C001"
+login_cd   = "taro"
+password   = "secret"
+
+[profiles.work.slack]
+token   = "xoxp-work"
+channel = "C01ABCDEF23"
+
+[profiles.side-company.kinnosuke]
+company_cd = "C999"
+base_url   = "https://staging.example.com/"
+`
+
+func writeConfig(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(body), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadAndProfile(t *testing.T) {
+	f, err := Load(writeConfig(t, sample), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := f.Profile("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Kinnosuke.CompanyCD != "C001" || p.Slack.Token != "xoxp-work" {
+		t.Errorf("default profile = %+v", p)
+	}
+
+	p, err = f.Profile("side-company")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Kinnosuke.BaseURL != "https://staging.example.com/" {
+		t.Errorf("side-company profile = %+v", p)
+	}
+
+	if _, err := f.Profile("nope"); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("err = %v, want ErrProfileNotFound", err)
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "none.toml")
+
+	f, err := Load(path, false)
+	if err != nil {
+		t.Fatalf("Load(optional): %v", err)
+	}
+	if p, err := f.Profile(""); err != nil || len(p.Env()) != 0 {
+		t.Errorf("Profile = %+v, %v; want empty", p, err)
+	}
+
+	if _, err := Load(path, true); err == nil {
+		t.Error("Load(mustExist) should fail for missing file")
+	}
+}
+
+func TestApplyEnvKeepsExistingValues(t *testing.T) {
+	t.Setenv("KIN_COMPANYCD", "from-env")
+	t.Setenv("KIN_LOGINCD", "")
+	t.Setenv("KIN_PASSWORD", "")
+
+	p := Profile{Kinnosuke: KinnosukeProfile{CompanyCD: "from-profile", LoginCD: "taro"}}
+	if err := p.ApplyEnv(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := os.Getenv("KIN_COMPANYCD"); got != "from-env" {
+		t.Errorf("KIN_COMPANYCD = %q, env must win over profile", got)
+	}
+	if got := os.Getenv("KIN_LOGINCD"); got != "taro" {
+		t.Errorf("KIN_LOGINCD = %q, want value from profile", got)
+	}
+	if got := os.Getenv("KIN_PASSWORD"); got != "" {
+		t.Errorf("KIN_PASSWORD = %q, want empty", got)
+	}
+}
+
+func TestLoadNotifiers(t *testing.T) {
+	f, err := Load(writeConfig(t, `
+[[profiles.work.notifiers]]
+type = "slack"
+
+[[profiles.work.notifiers]]
+type       = "teams"
+url_secret = "KN_TEAMS_WEBHOOK"
+start_text = "開始します（{mode}）"
+
+[[profiles.work.notifiers]]
+type    = "command"
+name    = "hook"
+command = ["notify-send", "kn"]
+`), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := f.Profile("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Notifiers) != 3 {
+		t.Fatalf("notifiers = %+v", p.Notifiers)
+	}
+	if n := p.Notifiers[1]; n.Type != "teams" || n.URLSecret != "KN_TEAMS_WEBHOOK" || n.StartText != "開始します（{mode}）" {
+		t.Errorf("teams = %+v", n)
+	}
+	if n := p.Notifiers[2]; n.Name != "hook" || len(n.Command) != 2 || n.Command[0] != "notify-send" {
+		t.Errorf("command = %+v", n)
+	}
+}
@@ -0,0 +1,107 @@
+package kinnosuke
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// 休暇残数ページの action（module は休暇申請と同じ）。
+const leaveBalanceAction = "balance"
+
+// 休暇残数の行見出し。
+const (
+	rowPaidLeaveRemaining   = "有休残日数"
+	rowPaidLeaveCarriedOver = "繰越日数"
+)
+
+// "12.5日" / "3日" 形式の日数
+var reDays = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*日`)
+
+// Balance は有休の残数と、月の残業時間の累計。
+type Balance struct {
+	// PaidLeaveRemaining は有休の残日数（繰越分を含む）。
+	PaidLeaveRemaining float64
+	// PaidLeaveCarriedOver は前年度からの繰越日数。
+	PaidLeaveCarriedOver float64
+	// Month は Overtime の対象月（1日 0:00）。
+	Month time.Time
+	// Overtime は Month の残業時間の累計（月次勤怠の各日の合計）。
+	Overtime time.Duration
+}
+
+// FetchBalance はログインして有休の残数と month の残業時間の累計を取得する。
+func FetchBalance(ctx context.Context, month time.Time) (*Balance, error) {
+	cli, err := NewWithOptions(OptionsFromEnv())
+	if err != nil {
+		return nil, err
+	}
+	defer cli.Close()
+	return cli.Balance(ctx, month)
+}
+
+// Balance は KIN_* の認証情報でログインし、休暇残数ページと month の月次勤怠を読み取る。
+func (c *Client) Balance(ctx context.Context, month time.Time) (*Balance, error) {
+	records, err := c.Timesheet(ctx, month) // ログインもここで行う
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := c.GetHTML(ctx, url.Values{
+		"module": {leaveModule},
+		"action": {leaveBalanceAction},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !authorized(src) {
+		return nil, fmt.Errorf("%w: session expired while reading leave balance", ErrUnauthorized)
+	}
+	b, err := ParseLeaveBalance(src)
+	if err != nil {
+		return nil, err
+	}
+
+	b.Month = time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, jst())
+	for _, r := range records {
+		b.Overtime += r.Overtime
+	}
+	return b, nil
+}
+
+// ParseLeaveBalance は休暇残数ページの HTML から有休の残日数と繰越日数を読み取る。
+func ParseLeaveBalance(src string) (*Balance, error) {
+	rows, ok := tableRows(src, "leave_balance_table")
+	if !ok {
+		return nil, ErrLeaveBalanceNotFound
+	}
+	values := map[string]string{}
+	for _, row := range rows {
+		if len(row.cells) >= 2 {
+			values[row.cells[0]] = row.cells[1]
+		}
+	}
+
+	b := &Balance{}
+	for _, f := range []struct {
+		label string
+		dst   *float64
+	}{
+		{rowPaidLeaveRemaining, &b.PaidLeaveRemaining},
+		{rowPaidLeaveCarriedOver, &b.PaidLeaveCarriedOver},
+	} {
+		v, ok := values[f.label]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s not found", ErrLeaveBalanceNotFound, f.label)
+		}
+		m := reDays.FindStringSubmatch(v)
+		if m == nil {
+			return nil, fmt.Errorf("%w: %s: invalid days %q", ErrLeaveBalanceNotFound, f.label, v)
+		}
+		*f.dst, _ = strconv.ParseFloat(m[1], 64)
+	}
+	return b, nil
+}
@@ -0,0 +1,45 @@
+package kinnosuke
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseLeaveBalance(t *testing.T) {
+	got, err := ParseLeaveBalance(readFixture(t, "leave_balance.html"))
+	if err != nil {
+		t.Fatalf("ParseLeaveBalance: %v", err)
+	}
+	if got.PaidLeaveRemaining != 12.5 || got.PaidLeaveCarriedOver != 3 {
+		t.Errorf("ParseLeaveBalance = %+v", got)
+	}
+
+	for _, src := range []string{
+		readFixture(t, "before_stamp.html"),
+		`<table class="leave_balance_table"><tr><th>有休残日数</th><td>-</td></tr></table>`,
+	} {
+		if _, err := ParseLeaveBalance(src); !errors.Is(err, ErrLeaveBalanceNotFound) {
+			t.Errorf("err = %v, want ErrLeaveBalanceNotFound", err)
+		}
+	}
+}
+
+func TestFetchBalance(t *testing.T) {
+	fs, _ := newFakeServer(t)
+
+	got, err := FetchBalance(t.Context(), time.Date(2026, time.October, 18, 0, 0, 0, 0, jst()))
+	if err != nil {
+		t.Fatalf("FetchBalance: %v", err)
+	}
+	// timesheet.html の残業 0:30 + 2:10
+	if got.Overtime != 2*time.Hour+40*time.Minute || got.PaidLeaveRemaining != 12.5 {
+		t.Errorf("FetchBalance = %+v", got)
+	}
+	if got.Month.Day() != 1 || got.Month.Month() != time.October {
+		t.Errorf("Month = %v", got.Month)
+	}
+	if fs.logins != 1 {
+		t.Errorf("logins = %d, want 1", fs.logins)
+	}
+}
@@ -1,70 +1,194 @@
 package kinnosuke
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
+	"os"
 	"strings"
 	"time"
+
+	"kintai/internal/auth"
+	"kintai/internal/retry"
+)
+
+const (
+	defaultBaseURL = "https://www.e4628.jp/"
+	defaultTimeout = 20 * time.Second
 )
 
-const siteURL = "https://www.e4628.jp/"
+// Options は Client の接続先や HTTP 周りの設定。
+// ゼロ値のフィールドはデフォルト値で補われる。
+type Options struct {
+	// BaseURL は勤之助のトップURL（例: https://www.e4628.jp/）。
+	// ローカルの偽サーバーやステージングのテナントに向けるときに指定する。
+	BaseURL string
+	// Timeout は1リクエストあたりのタイムアウト。全体の期限は各関数に渡す context で指定する。
+	Timeout time.Duration
+	// Transport は HTTP のトランスポート。nil なら http.DefaultTransport。
+	Transport http.RoundTripper
+	// UserAgent が空でなければ全リクエストの User-Agent に設定する。
+	UserAgent string
+	// SessionFile が空でなければ、Cookie をこのファイルに暗号化して保存し、次回の起動で再利用する。
+	SessionFile string
+	// SecretStore はセッションキャッシュの鍵（KN_SESSION_KEY）を読み書きするシークレットストア。
+	// nil なら環境変数 KN_SECRET_STORE などに従って開く（auth.SecretStoreFromEnv）。
+	SecretStore auth.SecretStore
+	// Retry は一時的な失敗（ネットワークの瞬断・5xx）の再試行のポリシー。ゼロ値のフィールドは retry.Default。
+	// 打刻などの冪等でない POST は、リクエストが届いていないと確かなときだけ再試行する。
+	Retry retry.Policy
+}
+
+// OptionsFromEnv は環境変数 KIN_BASE_URL / KN_SESSION_CACHE / KN_SESSION_FILE / KN_RETRY_* から Options を組み立てる。
+func OptionsFromEnv() Options {
+	return Options{
+		BaseURL:     strings.TrimSpace(os.Getenv("KIN_BASE_URL")),
+		SessionFile: sessionFileFromEnv(),
+		Retry:       retry.FromEnv(),
+	}
+}
 
 type Client struct {
-	http *http.Client
+	http      *http.Client
+	baseURL   string
+	userAgent string
+	retry     retry.Policy
+	// session はセッションキャッシュ（Options.SessionFile が空なら nil）。
+	session *persistentJar
 }
 
+// New はデフォルト設定（本番の勤之助）の Client を返す。
 func New() (*Client, error) {
-	jar, err := cookiejar.New(nil)
+	return NewWithOptions(Options{})
+}
+
+// NewWithOptions は opts に従って Client を生成する。
+func NewWithOptions(opts Options) (*Client, error) {
+	base := opts.BaseURL
+	if base == "" {
+		base = defaultBaseURL
+	}
+	u, err := url.Parse(base)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid base url: %q", base)
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+
+	var jar http.CookieJar
+	var session *persistentJar
+	if opts.SessionFile != "" {
+		session, err = newPersistentJar(u, opts.SessionFile, opts.SecretStore)
+		jar = session
+	} else {
+		jar, err = cookiejar.New(nil)
+	}
 	if err != nil {
 		return nil, err
 	}
 	return &Client{
 		http: &http.Client{
-			Jar:     jar,
-			Timeout: 20 * time.Second,
+			Jar:       jar,
+			Timeout:   timeout,
+			Transport: opts.Transport,
 		},
+		baseURL:   u.String(),
+		userAgent: opts.UserAgent,
+		retry:     opts.Retry,
+		session:   session,
 	}, nil
 }
 
-func (c *Client) GetTopHTML() (string, error) {
-	req, err := http.NewRequest(http.MethodGet, siteURL, nil)
-	if err != nil {
-		return "", err
+// Close はセッションキャッシュを有効にしている場合、使い終わった Client の Cookie をまとめて保存する。
+// 保存に失敗しても次回ログインし直すだけなので、呼び出し側はエラーを無視してよい。
+func (c *Client) Close() error {
+	if c.session == nil {
+		return nil
 	}
-	req.Header.Set("Cache-Control", "no-store")
+	return c.session.save()
+}
 
-	resp, err := c.http.Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
+// GetTopHTML はトップページを GET し、本文を返す。
+func (c *Client) GetTopHTML(ctx context.Context) (string, error) {
+	return c.GetHTML(ctx, nil)
+}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		b, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("GET top failed: %s body=%s", resp.Status, truncate(string(b), 200))
+// GetHTML はトップURLに query を付けて GET し、本文を返す。一時的な失敗は再試行する。
+// ctx がキャンセルされたら通信中のリクエストも中断する。
+func (c *Client) GetHTML(ctx context.Context, query url.Values) (string, error) {
+	u := c.baseURL
+	if len(query) > 0 {
+		u += "?" + query.Encode()
 	}
-	b, err := io.ReadAll(resp.Body)
+	var body string
+	err := retry.Do(ctx, c.retry, retryableIdempotent, func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return err
+		}
+		body, err = c.do(req)
+		return err
+	})
+	return body, err
+}
+
+// PostForm は params をフォームとして POST し、本文を返す。
+// 冪等とは限らないので、リクエストが届いていないと確かな失敗（接続できないなど）だけ再試行する。
+func (c *Client) PostForm(ctx context.Context, params map[string]string) (string, error) {
+	return c.postForm(ctx, c.baseURL, params, retryableNotSent)
+}
+
+// PostFormTo は params を action（フォームの action 属性。トップURLからの相対でもよい）に POST し、本文を返す。
+// 勤之助と別のホストには送らない。再試行は PostForm と同じ。
+func (c *Client) PostFormTo(ctx context.Context, action string, params map[string]string) (string, error) {
+	base, err := url.Parse(c.baseURL)
 	if err != nil {
 		return "", err
 	}
-	return string(b), nil
+	ref, err := url.Parse(action)
+	if err != nil {
+		return "", fmt.Errorf("invalid form action %q: %w", action, err)
+	}
+	u := base.ResolveReference(ref)
+	if u.Scheme != base.Scheme || u.Host != base.Host {
+		return "", fmt.Errorf("form action %q is not on %s", action, base.Host)
+	}
+	return c.postForm(ctx, u.String(), params, retryableNotSent)
 }
 
-func (c *Client) PostForm(params map[string]string) (string, error) {
+// postForm は params を u に POST し、classify が再試行可能と判定した失敗を再試行する。
+func (c *Client) postForm(ctx context.Context, u string, params map[string]string, classify retry.Classifier) (string, error) {
 	v := url.Values{}
 	for k, val := range params {
 		v.Set(k, val)
 	}
 
-	req, err := http.NewRequest(http.MethodPost, siteURL, strings.NewReader(v.Encode()))
-	if err != nil {
-		return "", err
-	}
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
-	req.Header.Set("Cache-Control", "no-store")
+	var body string
+	err := retry.Do(ctx, c.retry, classify, func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, strings.NewReader(v.Encode()))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+		body, err = c.do(req)
+		return err
+	})
+	return body, err
+}
+
+// do は req を送り、2xx なら本文を返す。それ以外は *ErrHTTPStatus を返す。
+func (c *Client) do(req *http.Request) (string, error) {
+	c.setHeaders(req)
 
 	resp, err := c.http.Do(req)
 	if err != nil {
@@ -74,7 +198,7 @@ func (c *Client) PostForm(params map[string]string) (string, error) {
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		b, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("POST failed: %s body=%s", resp.Status, truncate(string(b), 200))
+		return "", &ErrHTTPStatus{Method: req.Method, Code: resp.StatusCode, Status: resp.Status, Body: truncate(string(b), 200)}
 	}
 	b, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -83,6 +207,28 @@ func (c *Client) PostForm(params map[string]string) (string, error) {
 	return string(b), nil
 }
 
+// retryableIdempotent は GET やログインなど、何度送ってもよいリクエストの失敗を再試行するかを判定する。
+// ネットワークの一時的な失敗と、5xx・429 を再試行する。
+func retryableIdempotent(err error) (bool, time.Duration) {
+	var se *ErrHTTPStatus
+	if errors.As(err, &se) {
+		return se.Code >= 500 || se.Code == http.StatusTooManyRequests, 0
+	}
+	return retry.Transient(err), 0
+}
+
+// retryableNotSent は冪等でないリクエストの失敗のうち、相手に届いていないと確かなものだけ再試行する。
+func retryableNotSent(err error) (bool, time.Duration) {
+	return retry.NotSent(err), 0
+}
+
+func (c *Client) setHeaders(req *http.Request) {
+	req.Header.Set("Cache-Control", "no-store")
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+}
+
 func truncate(s string, n int) string {
 	if len(s) <= n {
 		return s
@@ -0,0 +1,59 @@
+package kinnosuke
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrUnauthorized はログインしてもトップページが未ログイン状態のままだったことを表す。
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrCSRFNotFound はトップページに打刻用の CSRF トークンが無かったことを表す。
+	ErrCSRFNotFound = errors.New("csrf token not found in top html")
+	// ErrStampButtonNotFound はトップページに打刻しようとした種別のボタン（休憩入りなど）が無かったことを表す。
+	// 打刻種別はボタンから読み取るので、ボタンが無ければ打刻しない。
+	ErrStampButtonNotFound = errors.New("stamp button not found")
+	// ErrStampNotConfirmed は打刻POST後のトップページで打刻時刻を確認できなかったことを表す。
+	ErrStampNotConfirmed = errors.New("stamp may have failed")
+	// ErrTimesheetNotFound は月次勤怠ページに勤怠の表が無かった（画面の構成が想定と違う）ことを表す。
+	ErrTimesheetNotFound = errors.New("timesheet table not found")
+	// ErrLeaveListNotFound は休暇申請一覧ページに申請の表が無かったことを表す。
+	ErrLeaveListNotFound = errors.New("leave list table not found")
+	// ErrLeaveBalanceNotFound は休暇残数ページに有休の残日数が無かったことを表す。
+	ErrLeaveBalanceNotFound = errors.New("leave balance not found")
+	// ErrRequestFormNotFound は申請フォームのページに、入力しようとした欄（出社・理由など）のあるフォームが無かったことを表す。
+	// 入力欄の name はフォームから読み取るので、欄が無ければ申請しない。
+	ErrRequestFormNotFound = errors.New("request form not found")
+	// ErrRequestNotConfirmed は申請の POST 後の画面で、申請の完了を確認できなかったことを表す。
+	ErrRequestNotConfirmed = errors.New("request may have failed")
+	// ErrRequestRejected は打刻修正・休暇などの申請が勤之助にエラーとして拒否されたことを表す（締め済みの日付など）。
+	ErrRequestRejected = errors.New("request rejected")
+)
+
+// ErrAlreadyStamped は本日すでに打刻済みのため打刻しなかったことを表す。
+type ErrAlreadyStamped struct {
+	// Label は打刻種別の表示名（出社・退社）。
+	Label string
+	// Time は既存の打刻時刻。
+	Time string
+}
+
+func (e *ErrAlreadyStamped) Error() string {
+	return fmt.Sprintf("already stamped: %s at %s (use --force to stamp again)", e.Label, e.Time)
+}
+
+// ErrHTTPStatus は勤之助が 2xx 以外のステータスを返したことを表す。
+type ErrHTTPStatus struct {
+	// Method は HTTP メソッド（GET / POST）。
+	Method string
+	// Code はステータスコード。
+	Code int
+	// Status はステータス行（"503 Service Unavailable" など）。
+	Status string
+	// Body はレスポンス本文の先頭。
+	Body string
+}
+
+func (e *ErrHTTPStatus) Error() string {
+	return fmt.Sprintf("%s failed: %s body=%s", e.Method, e.Status, e.Body)
+}
@@ -0,0 +1,268 @@
+package kinnosuke
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	fakeCompanyCD = "C001"
+	fakeLoginCD   = "taro"
+	fakePassword  = "secret"
+	fakeCSRFKey   = "__sectag_1a2b3c4d"
+	fakeCSRFValue = "0123456789abcdef0123456789abcdef"
+	sessionCookie = "kn_session"
+
+	// 申請フォーム（fix_form.html / leave_form.html）の CSRF トークン
+	fakeFormCSRFKey   = "__sectag_5e6f7a8b"
+	fakeFormCSRFValue = "fedcba9876543210fedcba9876543210"
+)
+
+// extraStamps は出社・退社以外の打刻種別ごとのボタン名と、打刻したときに返す時刻。
+// 種別は break_buttons_unverified.html・out_buttons_unverified.html のボタンに合わせる。
+var extraStamps = map[string]struct{ label, time string }{
+	"13": {"休憩入り", "12:00"},
+	"14": {"休憩戻り", "13:00"},
+	"15": {"外出", "14:00"},
+	"16": {"再入", "16:30"},
+}
+
+// fakeServer は勤之助のトップページとログイン・打刻POSTを模した httptest サーバー。
+// 状態に応じて testdata のフィクスチャを返す。
+type fakeServer struct {
+	t *testing.T
+
+	mu       sync.Mutex
+	started  bool
+	left     bool
+	extra    map[string]int // 出社・退社以外の種別ごとの打刻回数（ボタンに時刻を差し込んで返す）
+	restamps map[string]int // 出社・退社を打刻済みの状態で打刻し直した回数
+	logins   int
+	logouts  int
+	stamps   []string
+	notes    []string
+	noStamp  bool // true なら打刻POSTを受け付けても状態を変えない
+	// extraButtons はトップページの打刻ボタンの行に差し込むフィクスチャ（実際の画面で未確認のボタン）。
+	extraButtons []string
+	failPost     bool // true なら打刻POSTに500を返す
+
+	stampPosts     int           // 受けた打刻POSTの数（失敗させたものを含む）
+	failGets       int           // 残りこの回数だけ GET に503を返す
+	failAfterStamp bool          // true なら打刻POSTを受け付けたうえで502を返す（届いたのに応答が失われた状態）
+	delay          time.Duration // 0 でなければ応答の前にこの時間待つ（クライアントが切断したらやめる）
+
+	timesheetQuery url.Values   // 最後に受けた月次勤怠ページのクエリ
+	leaveQuery     url.Values   // 最後に受けた休暇申請一覧のクエリ
+	todayLeave     []string     // 空でなければ休暇申請一覧に当日の行（休暇区分・取得単位・承認状況）を足す
+	requests       []url.Values // 受け付けた申請（打刻修正・休暇）のフォーム（送信先のクエリを含む）
+	rejectRequest  bool         // true なら申請にエラー画面を返す
+	formPage       string       // 空でなければ申請フォームの代わりに返すフィクスチャ
+	unconfirmed    bool         // true なら申請に完了・エラーのどちらも表示しない画面を返す
+}
+
+func newFakeServer(t *testing.T) (*fakeServer, *httptest.Server) {
+	t.Helper()
+	fs := &fakeServer{t: t, extra: map[string]int{}, restamps: map[string]int{}}
+	srv := httptest.NewServer(fs)
+	t.Cleanup(srv.Close)
+	// 再試行の待ち時間を短くする
+	t.Setenv("KN_RETRY_BASE_DELAY", "1ms")
+	t.Setenv("KN_RETRY_MAX_DELAY", "1ms")
+	t.Setenv("KIN_BASE_URL", srv.URL)
+	t.Setenv("KIN_COMPANYCD", fakeCompanyCD)
+	t.Setenv("KIN_LOGINCD", fakeLoginCD)
+	t.Setenv("KIN_PASSWORD", fakePassword)
+	// KIN_PASSWORD を空にしたテストで開発者のキーリングを読みに行かないように
+	t.Setenv("KN_SECRET_STORE", "dotenv")
+	// 開発者のセッションキャッシュを読み書きしないように（session_test.go では有効にする）
+	t.Setenv("KN_SESSION_CACHE", "off")
+	return fs, srv
+}
+
+func (fs *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.delay > 0 {
+		select {
+		case <-time.After(fs.delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	loggedIn := false
+	if c, err := r.Cookie(sessionCookie); err == nil && c.Value == "ok" {
+		loggedIn = true
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if fs.failGets > 0 {
+			fs.failGets--
+			http.Error(w, "maintenance", http.StatusServiceUnavailable)
+			return
+		}
+		q := r.URL.Query()
+		switch {
+		case q.Get("module") == "logout":
+			fs.logouts++
+			http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: "", Path: "/", MaxAge: -1})
+			fs.writeTop(w, false)
+		case !loggedIn || q.Get("module") == "":
+			fs.writeTop(w, loggedIn)
+		case fs.formPage != "" && (q.Get("action") == "fix" || q.Get("action") == "apply"):
+			writeHTML(w, readFixture(fs.t, fs.formPage))
+		case q.Get("module") == "timesheet" && q.Get("action") == "fix":
+			writeHTML(w, readFixture(fs.t, "fix_form.html"))
+		case q.Get("module") == "timesheet":
+			fs.timesheetQuery = q
+			writeHTML(w, readFixture(fs.t, "timesheet.html"))
+		case q.Get("module") == "leave" && q.Get("action") == "apply":
+			writeHTML(w, readFixture(fs.t, "leave_form.html"))
+		case q.Get("module") == "leave" && q.Get("action") == "balance":
+			writeHTML(w, readFixture(fs.t, "leave_balance.html"))
+		case q.Get("module") == "leave":
+			fs.leaveQuery = q
+			fs.writeLeaveList(w)
+		default:
+			http.Error(w, "unknown module", http.StatusBadRequest)
+		}
+	case http.MethodPost:
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// 申請フォームは module・action を送信先のクエリに持つので、本文とクエリを合わせた r.Form で見る
+		switch r.Form.Get("module") {
+		case "timesheet", "leave":
+			// 打刻修正申請・休暇申請
+			if !loggedIn {
+				http.Error(w, "unauthorized", http.StatusForbidden)
+				return
+			}
+			if r.PostForm.Get(fakeFormCSRFKey) != fakeFormCSRFValue {
+				http.Error(w, "bad csrf", http.StatusForbidden)
+				return
+			}
+			fs.requests = append(fs.requests, r.Form)
+			name := "request_complete.html"
+			switch {
+			case fs.rejectRequest:
+				name = "request_error.html"
+			case fs.unconfirmed:
+				name = "before_stamp.html"
+			}
+			writeHTML(w, readFixture(fs.t, name))
+		case "login":
+			fs.logins++
+			if r.PostForm.Get("y_companycd") == fakeCompanyCD &&
+				r.PostForm.Get("y_logincd") == fakeLoginCD &&
+				r.PostForm.Get("password") == fakePassword {
+				http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: "ok", Path: "/"})
+				loggedIn = true
+			}
+			fs.writeTop(w, loggedIn)
+		case "timerecorder":
+			if !loggedIn {
+				http.Error(w, "unauthorized", http.StatusForbidden)
+				return
+			}
+			if r.PostForm.Get(fakeCSRFKey) != fakeCSRFValue {
+				http.Error(w, "bad csrf", http.StatusForbidden)
+				return
+			}
+			fs.stampPosts++
+			if fs.failPost {
+				http.Error(w, "boom", http.StatusInternalServerError)
+				return
+			}
+			typ := r.PostForm.Get("timerecorder_stamping_type")
+			fs.stamps = append(fs.stamps, typ)
+			if note := r.PostForm.Get("timerecorder_note"); note != "" {
+				fs.notes = append(fs.notes, note)
+			}
+			if !fs.noStamp {
+				switch {
+				case typ == "1" && fs.started, typ == "2" && fs.left:
+					fs.restamps[typ]++
+				case typ == "1":
+					fs.started = true
+				case typ == "2":
+					fs.left = true
+				default:
+					fs.extra[typ]++
+				}
+			}
+			if fs.failAfterStamp {
+				http.Error(w, "bad gateway", http.StatusBadGateway)
+				return
+			}
+			fs.writeTop(w, loggedIn)
+		default:
+			http.Error(w, "unknown module", http.StatusBadRequest)
+		}
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (fs *fakeServer) writeTop(w http.ResponseWriter, loggedIn bool) {
+	name := "logged_out.html"
+	switch {
+	case !loggedIn:
+	case fs.started && fs.left:
+		name = "after_leave.html"
+	case fs.started:
+		name = "after_start.html"
+	default:
+		name = "before_stamp.html"
+	}
+	src := readFixture(fs.t, name)
+	if loggedIn {
+		for _, extra := range fs.extraButtons {
+			src = strings.Replace(src, "</tr>\n</table>", readFixture(fs.t, extra)+"</tr>\n</table>", 1)
+		}
+	}
+	for typ, base := range map[string]string{"1": "09:00", "2": "18:30"} {
+		if n := fs.restamps[typ]; n > 0 {
+			src = strings.Replace(src, "("+base+")", "("+laterTime(base, n)+")", 1)
+		}
+	}
+	for typ, st := range extraStamps {
+		if n := fs.extra[typ]; n > 0 {
+			src = strings.Replace(src, ">"+st.label+"</button>", ">"+st.label+"<br>("+laterTime(st.time, n-1)+")</button>", 1)
+		}
+	}
+	writeHTML(w, src)
+}
+
+// laterTime は "12:00" 形式の base の n×10分後を返す。打ち直した打刻の時刻に使う。
+func laterTime(base string, n int) string {
+	t, err := time.Parse("15:04", base)
+	if err != nil {
+		panic(err)
+	}
+	return t.Add(time.Duration(n) * 10 * time.Minute).Format("15:04")
+}
+
+// writeLeaveList は休暇申請一覧を返す。todayLeave があれば当日の行を足す。
+func (fs *fakeServer) writeLeaveList(w http.ResponseWriter) {
+	src := readFixture(fs.t, "leave_list.html")
+	if len(fs.todayLeave) > 0 {
+		row := "<tr><td>" + time.Now().In(jst()).Format("2006/01/02") + "</td><td>" +
+			strings.Join(fs.todayLeave, "</td><td>") + "</td><td></td></tr>\n"
+		src = strings.Replace(src, "</tbody>", row+"</tbody>", 1)
+	}
+	writeHTML(w, src)
+}
+
+func writeHTML(w http.ResponseWriter, src string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write([]byte(src))
+}
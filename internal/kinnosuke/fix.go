@@ -0,0 +1,220 @@
+package kinnosuke
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// 打刻修正申請ページのクエリ。Web画面の「打刻修正申請」と同じ。
+// 送信先と hidden の値はこのページのフォームから読み取る。
+const (
+	fixModule     = "timesheet"
+	fixFormAction = "fix"
+)
+
+// 打刻修正申請フォームの入力欄の見出し。
+const (
+	fixLabelStart  = "出社"
+	fixLabelEnd    = "退社"
+	fixLabelReason = "理由"
+)
+
+var reClock = regexp.MustCompile(`^([01]\d|2[0-3]):[0-5]\d$`)
+
+// FixRequest は打刻修正申請1件分。
+type FixRequest struct {
+	// Date は修正する日。
+	Date time.Time
+	// Start / End は正しい出社・退社時刻（"09:05" 形式）。空ならその打刻は修正しない。
+	Start string
+	End   string
+	// Reason は申請理由。
+	Reason string
+}
+
+// Validate は申請内容の不足・形式の誤りをまとめて返す。ネットワークには接続しない。
+func (r FixRequest) Validate() error {
+	var errs []error
+	if r.Date.IsZero() {
+		errs = append(errs, errors.New("date is required"))
+	}
+	if r.Start == "" && r.End == "" {
+		errs = append(errs, errors.New("start or end is required"))
+	}
+	for _, t := range []struct{ name, val string }{{"start", r.Start}, {"end", r.End}} {
+		if t.val != "" && !reClock.MatchString(t.val) {
+			errs = append(errs, fmt.Errorf("%s must be HH:MM: %q", t.name, t.val))
+		}
+	}
+	if reClock.MatchString(r.Start) && reClock.MatchString(r.End) && r.Start >= r.End {
+		errs = append(errs, fmt.Errorf("start %s must be before end %s", r.Start, r.End))
+	}
+	if strings.TrimSpace(r.Reason) == "" {
+		errs = append(errs, errors.New("reason is required"))
+	}
+	return errors.Join(errs...)
+}
+
+// SubmitOptions は申請（RequestFix / ApplyLeave）の動作の指定。
+type SubmitOptions struct {
+	// DryRun が true なら申請フォームの CSRF トークンを取るところまで行い、POST はしない。
+	DryRun bool
+}
+
+// RequestFix はログインして打刻修正申請フォームを送信し、送信した（DryRun なら送信する予定の）フォームの内容を返す。
+// 入力欄の name・送信先・CSRF トークンは、打刻修正申請のページのフォームから読み取る。
+// 修正しない時刻（空の Start / End）の欄は、フォームの初期値のまま送る。
+func RequestFix(ctx context.Context, req FixRequest, opts SubmitOptions) (map[string]string, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	cred, err := loadCredentialFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	cli, err := NewWithOptions(OptionsFromEnv())
+	if err != nil {
+		return nil, err
+	}
+	defer cli.Close()
+	if _, err := ensureAuthorized(ctx, cli, cred); err != nil {
+		return nil, err
+	}
+
+	inputs := map[string]string{fixLabelReason: req.Reason}
+	if req.Start != "" {
+		inputs[fixLabelStart] = req.Start
+	}
+	if req.End != "" {
+		inputs[fixLabelEnd] = req.End
+	}
+	return submitForm(ctx, cli,
+		url.Values{"module": {fixModule}, "action": {fixFormAction}, "date": {req.Date.Format("2006-01-02")}},
+		inputs, nil, opts.DryRun)
+}
+
+// submitForm は formQuery の申請フォームのページを開き、inputs（入力欄の見出し → 値）を入れ、
+// choices（選択欄の見出し → 選択肢の表示名）の選択肢を選んだフォームを、フォームの action に POST する。
+// hidden の値（CSRF トークンを含む）と他の入力欄の初期値はそのまま送る。
+// 入力欄・選択肢と CSRF トークンがそろったフォームが無ければ、POST せず ErrRequestFormNotFound
+// （CSRF トークンを持つフォームが無ければ ErrCSRFNotFound）を返す。
+// 勤之助がエラーを表示したら ErrRequestRejected、完了画面でなければ ErrRequestNotConfirmed を返す。
+// dryRun なら POST せずに送信する予定の内容を返す。
+func submitForm(ctx context.Context, cli *Client, formQuery url.Values, inputs, choices map[string]string, dryRun bool) (map[string]string, error) {
+	module := formQuery.Get("module")
+	src, err := cli.GetHTML(ctx, formQuery)
+	if err != nil {
+		return nil, err
+	}
+	if !ParseTopPage(src).Authorized() {
+		return nil, fmt.Errorf("%w: session expired while opening %s form", ErrUnauthorized, module)
+	}
+	form, err := findForm(parseForms(src), inputs, choices)
+	if err != nil {
+		return nil, fmt.Errorf("%s form: %w", module, err)
+	}
+
+	params := maps.Clone(form.Values)
+	for label, v := range inputs {
+		params[form.Names[label]] = v
+	}
+	for label, option := range choices {
+		name := form.Names[label]
+		params[name] = form.Options[name][option]
+	}
+	if dryRun {
+		return params, nil
+	}
+
+	res, err := cli.PostFormTo(ctx, form.Action, params)
+	if err != nil {
+		return nil, fmt.Errorf("%s request failed: %w", module, err)
+	}
+	if msg, ok := textByClass(res, "error_message"); ok {
+		return nil, fmt.Errorf("%w: %s", ErrRequestRejected, msg)
+	}
+	if _, ok := textByClass(res, "complete_message"); !ok {
+		return nil, fmt.Errorf("%w: %s request was not confirmed", ErrRequestNotConfirmed, module)
+	}
+	return params, nil
+}
+
+// findForm は CSRF トークンを持ち、inputs のすべての見出しの入力欄と、choices のすべての選択肢があるフォームを返す。
+// 無ければ、最も多くそろったフォームに足りない入力欄・選択肢をエラーにする。
+func findForm(forms []*requestForm, inputs, choices map[string]string) (*requestForm, error) {
+	var missing []string
+	for _, f := range forms {
+		if f.CSRFKey == "" {
+			continue
+		}
+		var m []string
+		for label := range inputs {
+			if _, ok := f.Names[label]; !ok {
+				m = append(m, label)
+			}
+		}
+		for label, option := range choices {
+			if _, ok := f.Options[f.Names[label]][option]; !ok {
+				m = append(m, label+"="+option)
+			}
+		}
+		if len(m) == 0 {
+			return f, nil
+		}
+		if missing == nil || len(m) < len(missing) {
+			missing = m
+		}
+	}
+	if missing == nil {
+		return nil, ErrCSRFNotFound
+	}
+	slices.Sort(missing)
+	return nil, fmt.Errorf("%w: missing %s", ErrRequestFormNotFound, strings.Join(missing, ", "))
+}
+
+// textByClass は class を持つ最初の要素の中のテキストを空白区切りで返す。
+func textByClass(src, class string) (string, bool) {
+	z := html.NewTokenizer(strings.NewReader(src))
+	var (
+		depth int // 対象要素の中のネスト深さ（0なら外）
+		found bool
+		texts []string
+	)
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return strings.Join(texts, " "), found
+		case html.StartTagToken:
+			tok := z.Token()
+			switch {
+			case depth > 0:
+				depth++
+			case !found && hasClass(tok, class):
+				found = true
+				depth = 1
+			}
+		case html.EndTagToken:
+			if depth > 0 {
+				depth--
+				if depth == 0 {
+					return strings.Join(texts, " "), true
+				}
+			}
+		case html.TextToken:
+			if depth > 0 {
+				if t := strings.TrimSpace(string(z.Text())); t != "" {
+					texts = append(texts, t)
+				}
+			}
+		}
+	}
+}
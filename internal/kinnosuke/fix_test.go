@@ -0,0 +1,124 @@
+package kinnosuke
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func testFixRequest() FixRequest {
+	return FixRequest{
+		Date:   time.Date(2026, time.October, 15, 0, 0, 0, 0, jst()),
+		Start:  "09:05",
+		End:    "18:30",
+		Reason: "退社打刻忘れ",
+	}
+}
+
+func TestFixRequestValidate(t *testing.T) {
+	if err := testFixRequest().Validate(); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+	for name, mod := range map[string]func(*FixRequest){
+		"no date":     func(r *FixRequest) { r.Date = time.Time{} },
+		"no times":    func(r *FixRequest) { r.Start, r.End = "", "" },
+		"bad start":   func(r *FixRequest) { r.Start = "9:05" },
+		"end < start": func(r *FixRequest) { r.End = "08:00" },
+		"no reason":   func(r *FixRequest) { r.Reason = " " },
+	} {
+		r := testFixRequest()
+		mod(&r)
+		if err := r.Validate(); err == nil {
+			t.Errorf("%s: Validate must fail", name)
+		}
+	}
+}
+
+func TestRequestFix(t *testing.T) {
+	fs, _ := newFakeServer(t)
+
+	got, err := RequestFix(t.Context(), testFixRequest(), SubmitOptions{})
+	if err != nil {
+		t.Fatalf("RequestFix: %v", err)
+	}
+	if got[fakeFormCSRFKey] != fakeFormCSRFValue || got["fix[date]"] != "2026-10-15" {
+		t.Errorf("payload = %v", got)
+	}
+	if len(fs.requests) != 1 {
+		t.Fatalf("requests = %d, want 1", len(fs.requests))
+	}
+	// 入力欄の name と送信先（action のクエリ）はフォームから読み取ったもの
+	f := fs.requests[0]
+	if f.Get("fix[start]") != "09:05" || f.Get("fix[end]") != "18:30" || f.Get("fix[comment]") != "退社打刻忘れ" {
+		t.Errorf("posted form = %v", f)
+	}
+	if f.Get("action") != "fix_request" {
+		t.Errorf("posted action = %q, want fix_request (from the form action)", f.Get("action"))
+	}
+}
+
+func TestRequestFixKeepsUnchangedInput(t *testing.T) {
+	fs, _ := newFakeServer(t)
+
+	// 修正しない出社の欄はフォームの初期値のまま送る
+	req := testFixRequest()
+	req.Start = ""
+	if _, err := RequestFix(t.Context(), req, SubmitOptions{}); err != nil {
+		t.Fatalf("RequestFix: %v", err)
+	}
+	if got := fs.requests[0].Get("fix[start]"); got != "09:00" {
+		t.Errorf("fix[start] = %q, want the form default 09:00", got)
+	}
+}
+
+func TestRequestFixFormNotFound(t *testing.T) {
+	fs, _ := newFakeServer(t)
+	// 申請フォームの代わりにトップページが返ってきても、打刻フォームの CSRF トークンで申請しない
+	fs.formPage = "before_stamp.html"
+
+	_, err := RequestFix(t.Context(), testFixRequest(), SubmitOptions{})
+	if !errors.Is(err, ErrRequestFormNotFound) {
+		t.Fatalf("err = %v, want ErrRequestFormNotFound", err)
+	}
+	if len(fs.requests) != 0 {
+		t.Errorf("must not post: requests = %v", fs.requests)
+	}
+}
+
+func TestRequestFixNotConfirmed(t *testing.T) {
+	fs, _ := newFakeServer(t)
+	fs.unconfirmed = true
+
+	_, err := RequestFix(t.Context(), testFixRequest(), SubmitOptions{})
+	if !errors.Is(err, ErrRequestNotConfirmed) {
+		t.Errorf("err = %v, want ErrRequestNotConfirmed", err)
+	}
+}
+
+func TestRequestFixDryRun(t *testing.T) {
+	fs, _ := newFakeServer(t)
+
+	got, err := RequestFix(t.Context(), testFixRequest(), SubmitOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("RequestFix: %v", err)
+	}
+	if got[fakeFormCSRFKey] != fakeFormCSRFValue || got["fix[end]"] != "18:30" {
+		t.Errorf("payload = %v", got)
+	}
+	if len(fs.requests) != 0 {
+		t.Errorf("dry run must not post: requests = %v", fs.requests)
+	}
+}
+
+func TestRequestFixRejected(t *testing.T) {
+	fs, _ := newFakeServer(t)
+	fs.rejectRequest = true
+
+	_, err := RequestFix(t.Context(), testFixRequest(), SubmitOptions{})
+	if !errors.Is(err, ErrRequestRejected) {
+		t.Fatalf("err = %v, want ErrRequestRejected", err)
+	}
+	if want := "request rejected: 締め処理済みの日付は申請できません。"; err.Error() != want {
+		t.Errorf("err = %q, want %q", err, want)
+	}
+}
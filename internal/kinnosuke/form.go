@@ -0,0 +1,206 @@
+package kinnosuke
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// requestForm は申請フォームのページの form 要素から読み取った、送信先と入力欄。
+type requestForm struct {
+	// Action は form の action 属性。空ならフォームのページ自身に送る。
+	Action string
+	// Values はブラウザが送信する入力欄の name → 値（hidden・入力欄の初期値・選択中の選択肢）。
+	Values map[string]string
+	// Names は入力欄の見出し（表の th や label のテキスト）→ 入力欄の name。
+	Names map[string]string
+	// Options は select の name → 選択肢の表示名 → 値。
+	Options map[string]map[string]string
+	// CSRFKey / CSRFValue はフォーム内の __sectag_xxx hidden フィールド。
+	CSRFKey   string
+	CSRFValue string
+}
+
+// parseForms はページ内の form 要素をすべて読み取る。
+// 入力欄の見出しは、直前（または入力欄を囲む）th・label のテキストとする。
+func parseForms(src string) []*requestForm {
+	z := html.NewTokenizer(strings.NewReader(src))
+	var (
+		forms []*requestForm
+		f     *requestForm // 読み取り中の form（外なら nil）
+
+		heading     string   // 直前の見出しのテキスト（入力欄に対応付けたら空にする）
+		inHeading   string   // 読み取り中の見出しの閉じタグ名
+		headingText []string // 読み取り中の見出しのテキスト
+
+		inSelect    string // 読み取り中の select の name
+		selected    bool   // inSelect で selected の選択肢を読んだか
+		inOption    bool
+		optionValue string
+		optionHas   bool // option に value 属性があるか
+		optionSel   bool
+		optionText  []string
+
+		inTextarea string // 読み取り中の textarea の name
+	)
+
+	// label は入力欄に対応する見出しを返し、同じ見出しを次の入力欄に使わないよう空にする。
+	label := func() string {
+		l := heading
+		if inHeading != "" {
+			// <label>理由 <textarea> のように見出しが入力欄を囲んでいる
+			l = strings.Join(headingText, " ")
+			inHeading = ""
+		}
+		heading = ""
+		return l
+	}
+	addName := func(name string) {
+		if l := label(); l != "" {
+			if _, ok := f.Names[l]; !ok {
+				f.Names[l] = name
+			}
+		}
+	}
+	endOption := func() {
+		if !inOption {
+			return
+		}
+		inOption = false
+		text := strings.Join(optionText, " ")
+		value := optionValue
+		if !optionHas {
+			value = text
+		}
+		f.Options[inSelect][text] = value
+		// 選択中の選択肢（無ければ最初の選択肢）を送る
+		if _, ok := f.Values[inSelect]; !ok || (optionSel && !selected) {
+			f.Values[inSelect] = value
+		}
+		if optionSel {
+			selected = true
+		}
+	}
+
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			return forms
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			if tok.Data == "form" {
+				f = &requestForm{
+					Action:  attr(tok, "action"),
+					Values:  map[string]string{},
+					Names:   map[string]string{},
+					Options: map[string]map[string]string{},
+				}
+				forms = append(forms, f)
+				heading, inHeading = "", ""
+				continue
+			}
+			if f == nil {
+				continue
+			}
+			switch tok.Data {
+			case "th", "label":
+				if tt == html.StartTagToken && inHeading == "" {
+					inHeading = tok.Data
+					headingText = headingText[:0]
+				}
+			case "input":
+				name := attr(tok, "name")
+				if name == "" {
+					continue
+				}
+				switch strings.ToLower(attr(tok, "type")) {
+				case "hidden":
+					f.Values[name] = attr(tok, "value")
+					if strings.HasPrefix(name, "__sectag_") && f.CSRFKey == "" {
+						f.CSRFKey = name
+						f.CSRFValue = attr(tok, "value")
+					}
+				case "submit", "button", "reset", "image", "file":
+				case "checkbox", "radio":
+					addName(name)
+					if _, ok := attrOK(tok, "checked"); ok {
+						f.Values[name] = attr(tok, "value")
+					}
+				default:
+					addName(name)
+					f.Values[name] = attr(tok, "value")
+				}
+			case "select":
+				name := attr(tok, "name")
+				if name == "" || tt != html.StartTagToken {
+					continue
+				}
+				addName(name)
+				inSelect, selected = name, false
+				f.Options[name] = map[string]string{}
+			case "option":
+				if inSelect == "" {
+					continue
+				}
+				endOption()
+				inOption = true
+				optionValue, optionHas = attrOK(tok, "value")
+				_, optionSel = attrOK(tok, "selected")
+				optionText = optionText[:0]
+			case "textarea":
+				name := attr(tok, "name")
+				if name == "" || tt != html.StartTagToken {
+					continue
+				}
+				addName(name)
+				inTextarea = name
+				f.Values[name] = ""
+			}
+
+		case html.EndTagToken:
+			tok := z.Token()
+			switch {
+			case tok.Data == "form":
+				f = nil
+			case f == nil:
+			case tok.Data == inHeading:
+				heading = strings.Join(headingText, " ")
+				inHeading = ""
+			case tok.Data == "option":
+				endOption()
+			case tok.Data == "select" && inSelect != "":
+				endOption()
+				inSelect = ""
+			case tok.Data == "textarea":
+				inTextarea = ""
+			}
+
+		case html.TextToken:
+			if f == nil {
+				continue
+			}
+			text := strings.TrimSpace(string(z.Text()))
+			switch {
+			case inTextarea != "":
+				f.Values[inTextarea] = text
+			case text == "":
+			case inOption:
+				optionText = append(optionText, text)
+			case inHeading != "":
+				headingText = append(headingText, text)
+			}
+		}
+	}
+}
+
+// attrOK は属性の値と、属性があるかを返す。
+func attrOK(tok html.Token, key string) (string, bool) {
+	for _, a := range tok.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
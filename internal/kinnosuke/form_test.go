@@ -0,0 +1,45 @@
+package kinnosuke
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseForms(t *testing.T) {
+	src := `<form id="search" action="./?module=search"><input type="text" name="q"></form>
+<form method="post" action="./apply">
+<input type="hidden" name="__sectag_abc" value="0123">
+<input type="hidden" name="id" value="7">
+<label>日付 <input type="text" name="d" value="2026-10-15"></label>
+<table>
+<tr><th>区分</th><td><select name="k"><option value="1">有休</option><option value="2" selected>代休</option></select></td></tr>
+<tr><th>単位</th><td><select name="u"><option>全日</option><option>半日</option></select></td></tr>
+<tr><th>理由</th><td><textarea name="r">通院</textarea></td></tr>
+<tr><td><input type="checkbox" name="c" value="on"><input type="submit" name="go" value="申請"></td></tr>
+</table>
+</form>`
+
+	forms := parseForms(src)
+	if len(forms) != 2 {
+		t.Fatalf("forms = %d, want 2", len(forms))
+	}
+	f := forms[1]
+	if f.Action != "./apply" || f.CSRFKey != "__sectag_abc" || f.CSRFValue != "0123" {
+		t.Errorf("Action/CSRF = %q/%q/%q", f.Action, f.CSRFKey, f.CSRFValue)
+	}
+	wantNames := map[string]string{"日付": "d", "区分": "k", "単位": "u", "理由": "r"}
+	if !reflect.DeepEqual(f.Names, wantNames) {
+		t.Errorf("Names = %v, want %v", f.Names, wantNames)
+	}
+	// 未チェックの checkbox と submit は送らない。select は選択中（無ければ最初）の選択肢
+	wantValues := map[string]string{"__sectag_abc": "0123", "id": "7", "d": "2026-10-15", "k": "2", "u": "全日", "r": "通院"}
+	if !reflect.DeepEqual(f.Values, wantValues) {
+		t.Errorf("Values = %v, want %v", f.Values, wantValues)
+	}
+	if got := f.Options["k"]; got["代休"] != "2" || got["有休"] != "1" {
+		t.Errorf("Options[k] = %v", got)
+	}
+	if forms[0].CSRFKey != "" {
+		t.Errorf("search form CSRFKey = %q, want none", forms[0].CSRFKey)
+	}
+}
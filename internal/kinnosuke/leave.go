@@ -0,0 +1,272 @@
+package kinnosuke
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 休暇申請ページのクエリ。Web画面の「休暇申請」と同じ。
+// 申請の送信先と hidden の値は申請ページのフォームから読み取る。
+const (
+	leaveModule       = "leave"
+	leaveFormAction   = "apply"
+	leaveListAction   = "list"
+	leaveApprovedText = "承認済"
+)
+
+// 休暇申請フォームの入力欄の見出し。
+const (
+	leaveLabelType   = "休暇区分"
+	leaveLabelUnit   = "取得単位"
+	leaveLabelReason = "理由"
+)
+
+// LeaveTypes は --type に指定できる休暇の種類 → 勤之助の休暇区分の表示名。
+// フォームの値は申請フォームの選択肢から表示名で探す。
+var LeaveTypes = map[string]struct{ Label string }{
+	"paid":         {"有休"},
+	"special":      {"特別休暇"},
+	"substitute":   {"振替休日"},
+	"compensatory": {"代休"},
+}
+
+// 取得単位（全日・午前半休・午後半休）。
+const (
+	HalfNone = ""
+	HalfAM   = "am"
+	HalfPM   = "pm"
+	// HalfUnknown は休暇申請一覧の取得単位が全日・午前・午後のどれとも読み取れなかったことを表す（申請には使えない）。
+	HalfUnknown = "unknown"
+)
+
+// halfDays は取得単位 → 申請フォームの選択肢の表示名。
+var halfDays = map[string]struct{ Label string }{
+	HalfNone: {"全日"},
+	HalfAM:   {"午前半休"},
+	HalfPM:   {"午後半休"},
+}
+
+// "2026/11/03" / "2026/11/03(火)" 形式の日付セル
+var reLeaveDate = regexp.MustCompile(`^(\d{4})/(\d{1,2})/(\d{1,2})`)
+
+// 休暇申請一覧の列見出し。
+const (
+	colLeaveUnit   = "取得単位"
+	colLeaveReason = "理由"
+)
+
+// LeaveRequest は休暇申請1件分。
+type LeaveRequest struct {
+	// Type は LeaveTypes のキー（paid など）。
+	Type string
+	Date time.Time
+	// Half は HalfNone（全日）/ HalfAM / HalfPM。
+	Half   string
+	Reason string
+}
+
+// Validate は申請内容の誤りをまとめて返す。ネットワークには接続しない。
+func (r LeaveRequest) Validate() error {
+	var errs []error
+	if _, ok := LeaveTypes[r.Type]; !ok {
+		errs = append(errs, fmt.Errorf("type must be one of %s: %q",
+			strings.Join(slices.Sorted(maps.Keys(LeaveTypes)), ", "), r.Type))
+	}
+	if r.Date.IsZero() {
+		errs = append(errs, errors.New("date is required"))
+	}
+	if _, ok := halfDays[r.Half]; !ok {
+		errs = append(errs, fmt.Errorf("half must be am or pm: %q", r.Half))
+	}
+	return errors.Join(errs...)
+}
+
+// ApplyLeave はログインして休暇申請フォームを送信し、送信した（DryRun なら送信する予定の）フォームの内容を返す。
+// 休暇区分・取得単位の値は、フォームの選択肢から表示名（有休・午前半休など）で選ぶ。
+// 入力欄や選択肢がフォームに無ければ申請せず ErrRequestFormNotFound を返す。
+func ApplyLeave(ctx context.Context, req LeaveRequest, opts SubmitOptions) (map[string]string, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	cred, err := loadCredentialFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	cli, err := NewWithOptions(OptionsFromEnv())
+	if err != nil {
+		return nil, err
+	}
+	defer cli.Close()
+	if _, err := ensureAuthorized(ctx, cli, cred); err != nil {
+		return nil, err
+	}
+
+	return submitForm(ctx, cli,
+		url.Values{"module": {leaveModule}, "action": {leaveFormAction}, "date": {req.Date.Format("2006-01-02")}},
+		map[string]string{leaveLabelReason: req.Reason},
+		map[string]string{
+			leaveLabelType: LeaveTypes[req.Type].Label,
+			leaveLabelUnit: halfDays[req.Half].Label,
+		},
+		opts.DryRun)
+}
+
+// LeaveRecord は休暇申請一覧の1件分。
+type LeaveRecord struct {
+	Date time.Time
+	// Type は休暇区分の表示名（有休など）。
+	Type string
+	// Half は HalfNone（全日）/ HalfAM / HalfPM / HalfUnknown。
+	Half     string
+	Approval string
+	Reason   string
+}
+
+// Approved は承認済みかを返す。
+func (r LeaveRecord) Approved() bool { return r.Approval == leaveApprovedText }
+
+// FullDay は全日の休暇かを返す。取得単位が「全日」と明記されているときだけ true。
+func (r LeaveRecord) FullDay() bool { return r.Half == HalfNone }
+
+// Leaves はログインして month（年月のみ使う）の休暇申請一覧を取得する。
+func Leaves(ctx context.Context, month time.Time) ([]LeaveRecord, error) {
+	cli, err := NewWithOptions(OptionsFromEnv())
+	if err != nil {
+		return nil, err
+	}
+	defer cli.Close()
+	return cli.Leaves(ctx, month)
+}
+
+// Leaves は KIN_* の認証情報でログインし、month の休暇申請一覧を読み取る。
+func (c *Client) Leaves(ctx context.Context, month time.Time) ([]LeaveRecord, error) {
+	cred, err := loadCredentialFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ensureAuthorized(ctx, c, cred); err != nil {
+		return nil, err
+	}
+	return leaves(ctx, c, month)
+}
+
+// leaves はログイン済みの cli で休暇申請一覧を読み取る。
+func leaves(ctx context.Context, cli *Client, month time.Time) ([]LeaveRecord, error) {
+	src, err := cli.GetHTML(ctx, url.Values{
+		"module": {leaveModule},
+		"action": {leaveListAction},
+		"year":   {strconv.Itoa(month.Year())},
+		"month":  {strconv.Itoa(int(month.Month()))},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !authorized(src) {
+		return nil, fmt.Errorf("%w: session expired while reading leave list", ErrUnauthorized)
+	}
+	return ParseLeaves(src)
+}
+
+// ParseLeaves は休暇申請一覧ページの HTML から申請を読み取る。申請が無ければ空を返す。
+func ParseLeaves(src string) ([]LeaveRecord, error) {
+	rows, ok := tableRows(src, "leave_table")
+	if !ok {
+		return nil, ErrLeaveListNotFound
+	}
+
+	var (
+		cols    map[string]int
+		records []LeaveRecord
+	)
+	loc := jst()
+	for _, row := range rows {
+		if cols == nil {
+			if row.header {
+				cols = columnIndex(row.cells)
+			}
+			continue
+		}
+		cell := func(name string) string {
+			if j, ok := cols[name]; ok && j < len(row.cells) {
+				return row.cells[j]
+			}
+			return ""
+		}
+
+		m := reLeaveDate.FindStringSubmatch(cell(colDate))
+		if m == nil {
+			continue
+		}
+		y, _ := strconv.Atoi(m[1])
+		mon, _ := strconv.Atoi(m[2])
+		day, _ := strconv.Atoi(m[3])
+		r := LeaveRecord{
+			Date:     time.Date(y, time.Month(mon), day, 0, 0, 0, 0, loc),
+			Type:     cell(colLeaveType),
+			Approval: cell(colApproval),
+			Half:     parseLeaveUnit(cell(colLeaveUnit)),
+			Reason:   cell(colLeaveReason),
+		}
+		records = append(records, r)
+	}
+	if cols == nil {
+		return nil, fmt.Errorf("%w: header row not found", ErrLeaveListNotFound)
+	}
+	return records, nil
+}
+
+// parseLeaveUnit は取得単位のセルを Half にする。
+// 「午前」「午前休」のような表記揺れは半休として扱い、全休とみなすのは「全日」と明記されているときだけにする
+// （全休と誤判定すると打刻をスキップしてしまうため）。
+func parseLeaveUnit(label string) string {
+	switch {
+	case label == halfDays[HalfNone].Label:
+		return HalfNone
+	case strings.Contains(label, "午前"):
+		return HalfAM
+	case strings.Contains(label, "午後"):
+		return HalfPM
+	}
+	return HalfUnknown
+}
+
+// LeaveToday はログインして、今日が承認済みの全休ならその申請を返す。休暇でなければ nil。
+// 打刻と別の処理（Slack など）をまとめてスキップするかを先に判断するときに使う。
+func LeaveToday(ctx context.Context) (*LeaveRecord, error) {
+	cred, err := loadCredentialFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	cli, err := NewWithOptions(OptionsFromEnv())
+	if err != nil {
+		return nil, err
+	}
+	defer cli.Close()
+	if _, err := ensureAuthorized(ctx, cli, cred); err != nil {
+		return nil, err
+	}
+	return approvedLeaveOn(ctx, cli, time.Now().In(jst()))
+}
+
+// approvedLeaveOn は day が承認済みの全休なら、その申請を返す。
+func approvedLeaveOn(ctx context.Context, cli *Client, day time.Time) (*LeaveRecord, error) {
+	records, err := leaves(ctx, cli, day)
+	if err != nil {
+		return nil, err
+	}
+	y, m, d := day.Date()
+	for _, r := range records {
+		if ry, rm, rd := r.Date.Date(); ry == y && rm == m && rd == d && r.Approved() && r.FullDay() {
+			return &r, nil
+		}
+	}
+	return nil, nil
+}
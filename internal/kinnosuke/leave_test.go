@@ -0,0 +1,147 @@
+package kinnosuke
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLeaveRequestValidate(t *testing.T) {
+	date := time.Date(2026, time.November, 3, 0, 0, 0, 0, jst())
+	if err := (LeaveRequest{Type: "paid", Date: date, Half: HalfAM}).Validate(); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+	for _, r := range []LeaveRequest{
+		{Type: "vacation", Date: date},
+		{Type: "paid"},
+		{Type: "paid", Date: date, Half: "evening"},
+	} {
+		if err := r.Validate(); err == nil {
+			t.Errorf("Validate(%+v) must fail", r)
+		}
+	}
+}
+
+func TestApplyLeave(t *testing.T) {
+	fs, _ := newFakeServer(t)
+
+	req := LeaveRequest{Type: "paid", Date: time.Date(2026, time.November, 3, 0, 0, 0, 0, jst()), Half: HalfPM, Reason: "私用"}
+	if _, err := ApplyLeave(t.Context(), req, SubmitOptions{}); err != nil {
+		t.Fatalf("ApplyLeave: %v", err)
+	}
+	if len(fs.requests) != 1 {
+		t.Fatalf("requests = %d, want 1", len(fs.requests))
+	}
+	// 選択肢の値・入力欄の name・送信先はフォームから読み取ったもの
+	f := fs.requests[0]
+	if f.Get("module") != "leave" || f.Get("action") != "apply_request" ||
+		f.Get("leave[kind]") != "11" || f.Get("leave[unit]") != "22" ||
+		f.Get("leave[date]") != "2026-11-03" || f.Get("leave[comment]") != "私用" {
+		t.Errorf("posted form = %v", f)
+	}
+}
+
+func TestApplyLeaveOptionNotFound(t *testing.T) {
+	fs, _ := newFakeServer(t)
+	fs.formPage = "leave_form_no_half.html"
+
+	// フォームに午後半休の選択肢が無ければ、値を推測して申請しない
+	req := LeaveRequest{Type: "paid", Date: time.Date(2026, time.November, 3, 0, 0, 0, 0, jst()), Half: HalfPM, Reason: "私用"}
+	_, err := ApplyLeave(t.Context(), req, SubmitOptions{})
+	if !errors.Is(err, ErrRequestFormNotFound) || !strings.Contains(err.Error(), "午後半休") {
+		t.Fatalf("err = %v, want ErrRequestFormNotFound for 午後半休", err)
+	}
+	if len(fs.requests) != 0 {
+		t.Errorf("must not post: requests = %v", fs.requests)
+	}
+}
+
+func TestParseLeaves(t *testing.T) {
+	got, err := ParseLeaves(readFixture(t, "leave_list.html"))
+	if err != nil {
+		t.Fatalf("ParseLeaves: %v", err)
+	}
+	want := []LeaveRecord{
+		{Date: time.Date(2026, 11, 3, 0, 0, 0, 0, jst()), Type: "有休", Half: HalfNone, Approval: "承認済", Reason: "私用"},
+		{Date: time.Date(2026, 11, 10, 0, 0, 0, 0, jst()), Type: "有休", Half: HalfPM, Approval: "申請中", Reason: "通院"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseLeaves =\n%+v\nwant\n%+v", got, want)
+	}
+	if !got[0].Approved() || !got[0].FullDay() || got[1].Approved() || got[1].FullDay() {
+		t.Errorf("Approved/FullDay mismatch: %+v", got)
+	}
+
+	if _, err := ParseLeaves(readFixture(t, "before_stamp.html")); !errors.Is(err, ErrLeaveListNotFound) {
+		t.Errorf("err = %v, want ErrLeaveListNotFound", err)
+	}
+}
+
+func TestParseLeaveUnit(t *testing.T) {
+	tests := []struct {
+		label   string
+		want    string
+		fullDay bool
+	}{
+		{label: "全日", want: HalfNone, fullDay: true},
+		{label: "午前半休", want: HalfAM},
+		{label: "午前", want: HalfAM},
+		{label: "午後休", want: HalfPM},
+		{label: "時間休", want: HalfUnknown},
+		{label: "", want: HalfUnknown},
+	}
+	for _, tt := range tests {
+		r := LeaveRecord{Half: parseLeaveUnit(tt.label)}
+		if r.Half != tt.want || r.FullDay() != tt.fullDay {
+			t.Errorf("parseLeaveUnit(%q) = %q (full day %v), want %q (%v)", tt.label, r.Half, r.FullDay(), tt.want, tt.fullDay)
+		}
+	}
+}
+
+func TestLeaveToday(t *testing.T) {
+	fs, _ := newFakeServer(t)
+
+	got, err := LeaveToday(t.Context())
+	if err != nil || got != nil {
+		t.Fatalf("LeaveToday = %+v, %v; want nil, nil", got, err)
+	}
+
+	fs.todayLeave = []string{"特別休暇", "全日", "承認済"}
+	got, err = LeaveToday(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || got.Type != "特別休暇" {
+		t.Errorf("LeaveToday = %+v", got)
+	}
+}
+
+func TestLeaveTodayFullDayOnly(t *testing.T) {
+	tests := []struct {
+		name  string
+		leave []string
+		want  bool
+	}{
+		{name: "approved full day", leave: []string{"有休", "全日", "承認済"}, want: true},
+		{name: "pending", leave: []string{"有休", "全日", "申請中"}},
+		{name: "half day", leave: []string{"有休", "午前半休", "承認済"}},
+		{name: "morning", leave: []string{"有休", "午前", "承認済"}},
+		{name: "unknown unit", leave: []string{"有休", "", "承認済"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs, _ := newFakeServer(t)
+			fs.todayLeave = tt.leave
+
+			got, err := LeaveToday(t.Context())
+			if err != nil {
+				t.Fatalf("LeaveToday: %v", err)
+			}
+			if (got != nil) != tt.want {
+				t.Errorf("LeaveToday = %+v, want on leave = %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package kinnosuke
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	labelStart      = "出社"
+	labelLeave      = "退社"
+	labelBreakStart = "休憩入り"
+	labelBreakEnd   = "休憩戻り"
+	labelOut        = "外出"
+	labelBack       = "再入"
+)
+
+var (
+	reButtonID   = regexp.MustCompile(`^timerecorder_(\d+)$`)
+	reButtonCall = regexp.MustCompile(`timerecorderSubmit\(\s*'(\d+)'\s*\)`)
+	reStampedAt  = regexp.MustCompile(`\(\s*(\d\d:\d\d)\s*\)`)
+)
+
+// TopPage は勤之助トップページを構造化したもの。
+type TopPage struct {
+	// UserName はログイン中のユーザー名。未ログインなら空。
+	UserName string
+	// CSRFKey / CSRFValue は打刻フォームの __sectag_xxx hidden フィールド。
+	CSRFKey   string
+	CSRFValue string
+	// StartTime / LeaveTime は本日の出社・退社時刻（"09:00" 形式）。未打刻なら空。
+	StartTime string
+	LeaveTime string
+	// BreakStartTime / BreakEndTime は本日の休憩入り・休憩戻り時刻。未打刻なら空。
+	BreakStartTime string
+	BreakEndTime   string
+	// OutTime / BackTime は本日の外出・再入時刻。未打刻なら空。
+	OutTime  string
+	BackTime string
+	// Buttons はタイムレコーダーに表示されている打刻ボタン。
+	Buttons []StampButton
+
+	authorized bool
+}
+
+// StampButton はタイムレコーダーの打刻ボタン1つ分。
+type StampButton struct {
+	// Type は timerecorder_stamping_type に送る値（出社なら "1"）。
+	Type string
+	// Label はボタンの表示名（出社・退社など）。
+	Label string
+	// Time は打刻済みの場合の時刻。未打刻なら空。
+	Time string
+}
+
+// Authorized はログイン済みのページかどうかを返す。
+func (p *TopPage) Authorized() bool { return p.authorized }
+
+// HasCSRF は打刻用の CSRF トークンが取れているかを返す。
+func (p *TopPage) HasCSRF() bool { return p.CSRFKey != "" && p.CSRFValue != "" }
+
+// Button は表示名が label の打刻ボタンを返す。
+func (p *TopPage) Button(label string) (StampButton, bool) {
+	for _, b := range p.Buttons {
+		if b.Label == label {
+			return b, true
+		}
+	}
+	return StampButton{}, false
+}
+
+// ButtonByType は打刻種別が typ の打刻ボタンを返す。
+func (p *TopPage) ButtonByType(typ string) (StampButton, bool) {
+	for _, b := range p.Buttons {
+		if b.Type == typ {
+			return b, true
+		}
+	}
+	return StampButton{}, false
+}
+
+// ParseTopPage はトップページのHTMLを TopPage に変換する。
+// HTMLトークナイザで読み取り、取れなかった項目だけ従来の正規表現で補う。
+func ParseTopPage(src string) *TopPage {
+	p := parseDOM(src)
+
+	// フォールバック：マークアップが想定と違っても正規表現で拾えるものは拾う
+	if !p.authorized {
+		p.authorized = authorized(src)
+	}
+	if !p.HasCSRF() {
+		if k, v, ok := csrfToken(src); ok {
+			p.CSRFKey, p.CSRFValue = k, v
+		}
+	}
+
+	if b, ok := p.Button(labelStart); ok {
+		p.StartTime = b.Time
+	}
+	if b, ok := p.Button(labelLeave); ok {
+		p.LeaveTime = b.Time
+	}
+	if b, ok := p.Button(labelBreakStart); ok {
+		p.BreakStartTime = b.Time
+	}
+	if b, ok := p.Button(labelBreakEnd); ok {
+		p.BreakEndTime = b.Time
+	}
+	if b, ok := p.Button(labelOut); ok {
+		p.OutTime = b.Time
+	}
+	if b, ok := p.Button(labelBack); ok {
+		p.BackTime = b.Time
+	}
+	if p.StartTime == "" {
+		p.StartTime, _ = matchTime(reStartTime, src)
+	}
+	if p.LeaveTime == "" {
+		p.LeaveTime, _ = matchTime(reLeaveTime, src)
+	}
+	if p.BreakStartTime == "" {
+		p.BreakStartTime, _ = matchTime(reBreakStartTime, src)
+	}
+	if p.BreakEndTime == "" {
+		p.BreakEndTime, _ = matchTime(reBreakEndTime, src)
+	}
+	if p.OutTime == "" {
+		p.OutTime, _ = matchTime(reOutTime, src)
+	}
+	if p.BackTime == "" {
+		p.BackTime, _ = matchTime(reBackTime, src)
+	}
+	return p
+}
+
+func parseDOM(src string) *TopPage {
+	p := &TopPage{}
+	z := html.NewTokenizer(strings.NewReader(src))
+
+	var (
+		inUserName int // user_name div のネスト深さ（0なら外）
+		userName   strings.Builder
+
+		inButton   string // 読み取り中の打刻ボタンの閉じタグ名
+		buttonType string
+		buttonText []string
+	)
+
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			p.UserName = strings.TrimSpace(userName.String())
+			return p
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			switch {
+			case inUserName > 0:
+				if tok.Data == "div" && tt == html.StartTagToken {
+					inUserName++
+				}
+			case tok.Data == "div" && hasClass(tok, "user_name"):
+				p.authorized = true
+				if tt == html.StartTagToken {
+					inUserName = 1
+				}
+			case tok.Data == "input":
+				name := attr(tok, "name")
+				if strings.HasPrefix(name, "__sectag_") && p.CSRFKey == "" {
+					p.CSRFKey = name
+					p.CSRFValue = attr(tok, "value")
+				}
+			case inButton == "" && tt == html.StartTagToken:
+				if typ, ok := stampingType(tok); ok {
+					inButton = tok.Data
+					buttonType = typ
+					buttonText = buttonText[:0]
+				}
+			}
+
+		case html.EndTagToken:
+			tok := z.Token()
+			if inUserName > 0 && tok.Data == "div" {
+				inUserName--
+			}
+			if inButton != "" && tok.Data == inButton {
+				p.Buttons = append(p.Buttons, newStampButton(buttonType, buttonText))
+				inButton = ""
+			}
+
+		case html.TextToken:
+			text := strings.TrimSpace(string(z.Text()))
+			if text == "" {
+				continue
+			}
+			if inUserName > 0 {
+				if userName.Len() > 0 {
+					userName.WriteByte(' ')
+				}
+				userName.WriteString(text)
+			}
+			if inButton != "" {
+				buttonText = append(buttonText, text)
+			}
+		}
+	}
+}
+
+// stampingType は打刻ボタンの要素なら stamping_type を返す。
+// id="timerecorder_1" か onclick="timerecorderSubmit('1')" のどちらかで判定する。
+func stampingType(tok html.Token) (string, bool) {
+	if m := reButtonID.FindStringSubmatch(attr(tok, "id")); m != nil {
+		return m[1], true
+	}
+	if m := reButtonCall.FindStringSubmatch(attr(tok, "onclick")); m != nil {
+		return m[1], true
+	}
+	return "", false
+}
+
+func newStampButton(typ string, text []string) StampButton {
+	b := StampButton{Type: typ}
+	for _, t := range text {
+		if m := reStampedAt.FindStringSubmatch(t); m != nil {
+			b.Time = m[1]
+			continue
+		}
+		if b.Label == "" {
+			b.Label = t
+		}
+	}
+	return b
+}
+
+func attr(tok html.Token, key string) string {
+	for _, a := range tok.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func hasClass(tok html.Token, class string) bool {
+	for _, c := range strings.Fields(attr(tok, "class")) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
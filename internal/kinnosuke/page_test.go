@@ -0,0 +1,90 @@
+package kinnosuke
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTopPage(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    TopPage
+	}{
+		{
+			fixture: "logged_out.html",
+			want:    TopPage{},
+		},
+		{
+			fixture: "before_stamp.html",
+			want: TopPage{
+				UserName:  "山田 太郎",
+				CSRFKey:   "__sectag_1a2b3c4d",
+				CSRFValue: "0123456789abcdef0123456789abcdef",
+				Buttons: []StampButton{
+					{Type: "1", Label: "出社"},
+					{Type: "2", Label: "退社"},
+				},
+				authorized: true,
+			},
+		},
+		{
+			fixture: "after_leave_br_slash.html",
+			want: TopPage{
+				UserName:  "山田 太郎",
+				CSRFKey:   "__sectag_1a2b3c4d",
+				CSRFValue: "0123456789abcdef0123456789abcdef",
+				StartTime: "09:00",
+				LeaveTime: "18:30",
+				Buttons: []StampButton{
+					{Type: "1", Label: "出社", Time: "09:00"},
+					{Type: "2", Label: "退社", Time: "18:30"},
+				},
+				authorized: true,
+			},
+		},
+		{
+			// 属性順や空白が変わっても DOM パースなら読める
+			fixture: "reordered_attrs.html",
+			want: TopPage{
+				UserName:  "山田 太郎",
+				CSRFKey:   "__sectag_99ff00aa",
+				CSRFValue: "fedcba9876543210fedcba9876543210",
+				StartTime: "09:00",
+				Buttons: []StampButton{
+					{Type: "1", Label: "出社", Time: "09:00"},
+					{Type: "2", Label: "退社"},
+				},
+				authorized: true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			got := ParseTopPage(readFixture(t, tt.fixture))
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("ParseTopPage =\n%+v\nwant\n%+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTopPageRegexFallback(t *testing.T) {
+	// 打刻ボタンとして認識できないマークアップでも正規表現で時刻を拾う
+	src := `<div class="user_name">x</div><span>出社<br>(08:45)</span>` +
+		`<span>休憩入り<br/>(12:10)</span><span>外出<br>(14:05)</span>` +
+		`<input type="hidden" name="__sectag_ab" value="cd">`
+	got := ParseTopPage(src)
+	if got.StartTime != "08:45" {
+		t.Errorf("StartTime = %q, want 08:45", got.StartTime)
+	}
+	if got.BreakStartTime != "12:10" || got.BreakEndTime != "" {
+		t.Errorf("BreakStartTime/BreakEndTime = %q/%q, want 12:10/\"\"", got.BreakStartTime, got.BreakEndTime)
+	}
+	if got.OutTime != "14:05" || got.BackTime != "" {
+		t.Errorf("OutTime/BackTime = %q/%q, want 14:05/\"\"", got.OutTime, got.BackTime)
+	}
+	if len(got.Buttons) != 0 {
+		t.Errorf("Buttons = %v, want none", got.Buttons)
+	}
+}
@@ -1,17 +1,29 @@
 package kinnosuke
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"regexp"
+	"strings"
+
+	"kintai/internal/auth"
+	"kintai/internal/config"
+	"kintai/internal/retry"
 )
 
+// 以下の正規表現は ParseTopPage で DOM から取れなかったときのフォールバック。
 var (
 	reAuthorized = regexp.MustCompile(`<div class="user_name">`)
 	reCSRF       = regexp.MustCompile(`name="(__sectag_[0-9a-f]+)" value="([0-9a-f]+)"`)
 	reStartTime  = regexp.MustCompile(`>出社<br(?:\s*\/)?>\((\d\d:\d\d)\)`)
 	reLeaveTime  = regexp.MustCompile(`>退社<br(?:\s*\/)?>\((\d\d:\d\d)\)`)
+	// 休憩・外出系はテナントによってボタンがないので、見つからなくてもエラーにしない
+	reBreakStartTime = regexp.MustCompile(`>休憩入り<br(?:\s*\/)?>\((\d\d:\d\d)\)`)
+	reBreakEndTime   = regexp.MustCompile(`>休憩戻り<br(?:\s*\/)?>\((\d\d:\d\d)\)`)
+	reOutTime        = regexp.MustCompile(`>外出<br(?:\s*\/)?>\((\d\d:\d\d)\)`)
+	reBackTime       = regexp.MustCompile(`>再入<br(?:\s*\/)?>\((\d\d:\d\d)\)`)
 )
 
 type credential struct {
@@ -20,16 +32,65 @@ type credential struct {
 	Password  string
 }
 
+// loadCredentialFromEnv は KIN_* から認証情報を読む。
+// KIN_PASSWORD が環境変数に無ければシークレットストア（auth.SecretStore）から読む。
 func loadCredentialFromEnv() (credential, error) {
-	c := credential{
+	c, err := credentialFromEnv()
+	if err != nil {
+		return credential{}, err
+	}
+	if err := c.validate(); err != nil {
+		return credential{}, err
+	}
+	return c, nil
+}
+
+func credentialFromEnv() (credential, error) {
+	pass, err := auth.LookupSecret("KIN_PASSWORD")
+	if err != nil {
+		return credential{}, err
+	}
+	return credential{
 		CompanyCD: os.Getenv("KIN_COMPANYCD"),
 		LoginCD:   os.Getenv("KIN_LOGINCD"),
-		Password:  os.Getenv("KIN_PASSWORD"),
+		Password:  pass,
+	}, nil
+}
+
+func (c credential) validate() error {
+	var missing []string
+	for _, kv := range []struct{ key, val string }{
+		{"KIN_COMPANYCD", c.CompanyCD},
+		{"KIN_LOGINCD", c.LoginCD},
+		{"KIN_PASSWORD", c.Password},
+	} {
+		if kv.val == "" {
+			missing = append(missing, kv.key)
+		}
 	}
-	if c.CompanyCD == "" || c.LoginCD == "" || c.Password == "" {
-		return credential{}, errors.New("missing env: KIN_COMPANYCD / KIN_LOGINCD / KIN_PASSWORD")
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", config.ErrMissing, strings.Join(missing, " / "))
 	}
-	return c, nil
+	return nil
+}
+
+// ValidateEnv は勤之助の設定（KIN_*）の不足・形式の誤りをまとめて返す。ネットワークには接続しない。
+// 返すエラーは errors.Is で config.ErrMissing / config.ErrInvalid と照合できる。
+func ValidateEnv() error {
+	var errs []error
+	c, err := credentialFromEnv()
+	if err != nil {
+		errs = append(errs, err)
+	} else if err := c.validate(); err != nil {
+		errs = append(errs, err)
+	}
+	// ネットワークには接続しないので、セッションキャッシュも読まない
+	opts := OptionsFromEnv()
+	opts.SessionFile = ""
+	if _, err := NewWithOptions(opts); err != nil {
+		errs = append(errs, fmt.Errorf("%w: KIN_BASE_URL: %v", config.ErrInvalid, err))
+	}
+	return errors.Join(errs...)
 }
 
 func authorized(html string) bool { return reAuthorized.MatchString(html) }
@@ -42,106 +103,244 @@ func csrfToken(html string) (key, value string, ok bool) {
 	return m[1], m[2], true
 }
 
-func startTime(html string) (string, bool) {
-	m := reStartTime.FindStringSubmatch(html)
+// matchTime は re の1つ目のグループ（"12:00" 形式の時刻）を返す。
+func matchTime(re *regexp.Regexp, html string) (string, bool) {
+	m := re.FindStringSubmatch(html)
 	if m == nil || len(m) < 2 {
 		return "", false
 	}
 	return m[1], true
 }
 
-func leaveTime(html string) (string, bool) {
-	m := reLeaveTime.FindStringSubmatch(html)
-	if m == nil || len(m) < 2 {
-		return "", false
-	}
-	return m[1], true
-}
-
-func login(cli *Client, cred credential) error {
-	_, err := cli.PostForm(map[string]string{
+// login はログインフォームを POST する。ログインは何度送っても同じなので、5xx なども再試行する。
+func login(ctx context.Context, cli *Client, cred credential) error {
+	_, err := cli.postForm(ctx, cli.baseURL, map[string]string{
 		"module":      "login",
 		"y_companycd": cred.CompanyCD,
 		"y_logincd":   cred.LoginCD,
 		"password":    cred.Password,
 		"trycnt":      "1",
-	})
+	}, retryableIdempotent)
 	return err
 }
 
-func stamp(cli *Client, stampingType string, tokenKey string, tokenVal string) error {
-	_, err := cli.PostForm(map[string]string{
+func stamp(ctx context.Context, cli *Client, stampingType string, note string, tokenKey string, tokenVal string) error {
+	params := map[string]string{
 		"module":                     "timerecorder",
 		"action":                     "timerecorder",
 		tokenKey:                     tokenVal,
 		"timerecorder_stamping_type": stampingType,
-	})
+	}
+	if note != "" {
+		params["timerecorder_note"] = note
+	}
+	_, err := cli.PostForm(ctx, params)
 	return err
 }
 
 // 拡張より簡略：毎回ログイン前提でもOKだが、authorizedならスキップする
-func ensureAuthorized(cli *Client, cred credential) (string, error) {
-	top, err := cli.GetTopHTML()
+func ensureAuthorized(ctx context.Context, cli *Client, cred credential) (*TopPage, error) {
+	top, err := cli.GetTopHTML(ctx)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	if authorized(top) {
-		return top, nil
+	if page := ParseTopPage(top); page.Authorized() {
+		return page, nil
 	}
-	if err := login(cli, cred); err != nil {
-		return "", fmt.Errorf("login failed: %w", err)
+	if err := login(ctx, cli, cred); err != nil {
+		return nil, fmt.Errorf("login failed: %w", err)
 	}
-	top, err = cli.GetTopHTML()
+	top, err = cli.GetTopHTML(ctx)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	if !authorized(top) {
-		return "", errors.New("still unauthorized after login (credentials or SSO issue)")
+	page := ParseTopPage(top)
+	if !page.Authorized() {
+		return nil, fmt.Errorf("%w: still unauthorized after login (credentials or SSO issue)", ErrUnauthorized)
 	}
-	return top, nil
+	return page, nil
 }
 
-func StampStart() (string, error) { return doStamp("1") } // 出社
-func StampEnd() (string, error)   { return doStamp("2") } // 退社
+// Today はログインしてトップページを取得し、本日の打刻状況を返す。打刻はしない。
+func Today(ctx context.Context) (*TopPage, error) {
+	cred, err := loadCredentialFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	cli, err := NewWithOptions(OptionsFromEnv())
+	if err != nil {
+		return nil, err
+	}
+	defer cli.Close()
+	return ensureAuthorized(ctx, cli, cred)
+}
+
+// 打刻種別（timerecorder_stamping_type）。
+// 出社・退社以外（休憩・外出など）は定数にせず、打刻前のトップページのボタンから読み取る。
+const (
+	StampTypeStart = "1" // 出社
+	StampTypeLeave = "2" // 退社
+)
 
-func doStamp(stType string) (string, error) {
+// stampKinds は打刻種別ごとの表示名と、TopPage 上の時刻。
+var stampKinds = map[string]struct {
+	label string
+	time  func(*TopPage) string
+}{
+	StampTypeStart: {labelStart, func(p *TopPage) string { return p.StartTime }},
+	StampTypeLeave: {labelLeave, func(p *TopPage) string { return p.LeaveTime }},
+}
+
+// StampOptions は打刻のオプション。
+type StampOptions struct {
+	// Force が true なら打刻済みでも打刻する（出社・退社。休憩・外出系は打刻済みでも打刻する）。
+	Force bool
+	// Type は打刻種別。空なら StampStart は出社、StampEnd は退社。
+	// 休憩などは、空ならトップページの該当するボタン（休憩入りなど）の種別を使う。
+	// 直行など、テナントで別の種別を出社扱いにしている場合に指定する。
+	Type string
+	// Note は打刻に添える備考。空なら送らない。
+	Note string
+}
+
+// StampStart は出社打刻し、打刻時刻を返す。
+// opts.Force が false で既に打刻済みなら *ErrAlreadyStamped を返す。
+func StampStart(ctx context.Context, opts StampOptions) (string, error) {
+	if opts.Type == "" {
+		opts.Type = StampTypeStart
+	}
+	return doStamp(ctx, opts, labelStart, true)
+}
+
+// StampEnd は退社打刻し、打刻時刻を返す。
+// opts.Force が false で既に打刻済みなら *ErrAlreadyStamped を返す。
+func StampEnd(ctx context.Context, opts StampOptions) (string, error) {
+	if opts.Type == "" {
+		opts.Type = StampTypeLeave
+	}
+	return doStamp(ctx, opts, labelLeave, true)
+}
+
+// StampBreakStart は休憩入りを打刻し、打刻時刻を返す。
+// 打刻種別はトップページの「休憩入り」ボタンから読み取り、ボタンが無ければ打刻せず ErrStampButtonNotFound を返す。
+// 休憩は1日に何度でも取れるので、打刻済みでも打刻する（opts.Force は使わない）。
+func StampBreakStart(ctx context.Context, opts StampOptions) (string, error) {
+	return doStamp(ctx, opts, labelBreakStart, false)
+}
+
+// StampBreakEnd は休憩戻りを打刻し、打刻時刻を返す。
+// 打刻種別はトップページの「休憩戻り」ボタンから読み取り、ボタンが無ければ打刻せず ErrStampButtonNotFound を返す。
+// 休憩は1日に何度でも取れるので、打刻済みでも打刻する（opts.Force は使わない）。
+func StampBreakEnd(ctx context.Context, opts StampOptions) (string, error) {
+	return doStamp(ctx, opts, labelBreakEnd, false)
+}
+
+// StampOut は外出を打刻し、打刻時刻を返す。
+// 打刻種別はトップページの「外出」ボタンから読み取り、ボタンが無ければ打刻せず ErrStampButtonNotFound を返す。
+// 外出は1日に何度でもできるので、打刻済みでも打刻する（opts.Force は使わない）。
+func StampOut(ctx context.Context, opts StampOptions) (string, error) {
+	return doStamp(ctx, opts, labelOut, false)
+}
+
+// StampBack は再入を打刻し、打刻時刻を返す。
+// 打刻種別はトップページの「再入」ボタンから読み取り、ボタンが無ければ打刻せず ErrStampButtonNotFound を返す。
+// 再入は1日に何度でもできるので、打刻済みでも打刻する（opts.Force は使わない）。
+func StampBack(ctx context.Context, opts StampOptions) (string, error) {
+	return doStamp(ctx, opts, labelBack, false)
+}
+
+// doStamp は opts.Type を打刻し、打刻時刻を返す。opts.Type が空なら、打刻前のトップページで表示名が button の
+// ボタンの種別を使う（推測した種別は送らない）。ボタンが無ければ打刻せず ErrStampButtonNotFound を返す。
+// oncePerDay（出社・退社）なら、opts.Force が false で既に打刻済みのときは打刻せず *ErrAlreadyStamped を返す。
+func doStamp(ctx context.Context, opts StampOptions, button string, oncePerDay bool) (string, error) {
 	cred, err := loadCredentialFromEnv()
 	if err != nil {
 		return "", err
 	}
-	cli, err := New()
+	cli, err := NewWithOptions(OptionsFromEnv())
 	if err != nil {
 		return "", err
 	}
+	defer cli.Close()
 
-	top, err := ensureAuthorized(cli, cred)
+	top, err := ensureAuthorized(ctx, cli, cred)
 	if err != nil {
 		return "", err
 	}
 
-	tk, tv, ok := csrfToken(top)
-	if !ok {
-		return "", errors.New("csrf token not found in top html")
+	if opts.Type == "" {
+		b, ok := top.Button(button)
+		if !ok {
+			return "", fmt.Errorf("%w: %s", ErrStampButtonNotFound, button)
+		}
+		opts.Type = b.Type
+	}
+	if oncePerDay && !opts.Force {
+		if err := checkNotStamped(top, opts.Type); err != nil {
+			return "", err
+		}
+	}
+
+	if !top.HasCSRF() {
+		return "", ErrCSRFNotFound
 	}
 
-	if err := stamp(cli, stType, tk, tv); err != nil {
-		return "", fmt.Errorf("stamp failed: %w", err)
+	// 打刻のPOSTは二重打刻を避けるため再試行しない。
+	// 届いたかわからない失敗（5xx・応答の途中での切断など）は、トップページで打刻されたかを確かめる。
+	stampErr := stamp(ctx, cli, opts.Type, opts.Note, top.CSRFKey, top.CSRFValue)
+	if stampErr != nil && retry.NotSent(stampErr) {
+		return "", fmt.Errorf("stamp failed: %w", stampErr)
 	}
 
-	after, err := cli.GetTopHTML()
+	html, err := cli.GetTopHTML(ctx)
 	if err != nil {
+		if stampErr != nil {
+			return "", fmt.Errorf("stamp failed: %w", stampErr)
+		}
 		return "", err
 	}
+	// 打刻前から時刻があった（--force・2回目の休憩など）場合、同じ時刻のままなら今回の打刻が通ったとはいえない
+	t, ok := stampedTime(ParseTopPage(html), opts.Type)
+	if before, _ := stampedTime(top, opts.Type); ok && t != before {
+		return t, nil
+	}
+	if stampErr != nil {
+		return "", fmt.Errorf("stamp failed: %w", stampErr)
+	}
+	if ok {
+		return "", fmt.Errorf("%w: %s time unchanged after stamping (%s)", ErrStampNotConfirmed, stampLabel(top, opts.Type), t)
+	}
+	return "", fmt.Errorf("%w: %s time not found after stamping", ErrStampNotConfirmed, stampLabel(top, opts.Type))
+}
 
-	if stType == "1" {
-		if t, ok := startTime(after); ok {
-			return t, nil
-		}
-		return "", errors.New("stamp may have failed: start time not found after stamping")
+// stampedTime は page から打刻種別 stType の打刻時刻を探す。
+// 打刻ボタンから取れなければ、既知の種別は ParseTopPage が正規表現で拾った時刻を使う。
+func stampedTime(page *TopPage, stType string) (string, bool) {
+	if b, ok := page.ButtonByType(stType); ok && b.Time != "" {
+		return b.Time, true
+	}
+	if k, ok := stampKinds[stType]; ok {
+		t := k.time(page)
+		return t, t != ""
 	}
+	return "", false
+}
 
-	if t, ok := leaveTime(after); ok {
-		return t, nil
+// stampLabel はエラーメッセージ用の打刻種別の表示名を返す。
+func stampLabel(page *TopPage, stType string) string {
+	if b, ok := page.ButtonByType(stType); ok && b.Label != "" {
+		return b.Label
+	}
+	if k, ok := stampKinds[stType]; ok {
+		return k.label
+	}
+	return "type " + stType
+}
+
+// checkNotStamped は打刻前のトップページから既存の打刻を探し、あれば *ErrAlreadyStamped を返す。
+func checkNotStamped(top *TopPage, stType string) error {
+	if t, ok := stampedTime(top, stType); ok {
+		return &ErrAlreadyStamped{Label: stampLabel(top, stType), Time: t}
 	}
-	return "", errors.New("stamp may have failed: leave time not found after stamping")
+	return nil
 }
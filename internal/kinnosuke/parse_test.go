@@ -0,0 +1,59 @@
+package kinnosuke
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func readFixture(t *testing.T, name string) string {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("read fixture %s: %v", name, err)
+	}
+	return string(b)
+}
+
+func TestParseFixtures(t *testing.T) {
+	tests := []struct {
+		fixture    string
+		authorized bool
+		csrf       bool
+		start      string
+		leave      string
+	}{
+		{fixture: "logged_out.html"},
+		{fixture: "before_stamp.html", authorized: true, csrf: true},
+		{fixture: "after_start.html", authorized: true, csrf: true, start: "09:00"},
+		{fixture: "after_leave.html", authorized: true, csrf: true, start: "09:00", leave: "18:30"},
+		{fixture: "after_leave_br_slash.html", authorized: true, csrf: true, start: "09:00", leave: "18:30"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			html := readFixture(t, tt.fixture)
+
+			if got := authorized(html); got != tt.authorized {
+				t.Errorf("authorized = %v, want %v", got, tt.authorized)
+			}
+
+			key, val, ok := csrfToken(html)
+			if ok != tt.csrf {
+				t.Fatalf("csrfToken ok = %v, want %v", ok, tt.csrf)
+			}
+			if ok && (key != "__sectag_1a2b3c4d" || val != "0123456789abcdef0123456789abcdef") {
+				t.Errorf("csrfToken = %q, %q", key, val)
+			}
+
+			got, ok := matchTime(reStartTime, html)
+			if ok != (tt.start != "") || got != tt.start {
+				t.Errorf("startTime = %q, %v, want %q", got, ok, tt.start)
+			}
+			got, ok = matchTime(reLeaveTime, html)
+			if ok != (tt.leave != "") || got != tt.leave {
+				t.Errorf("leaveTime = %q, %v, want %q", got, ok, tt.leave)
+			}
+		})
+	}
+}
@@ -0,0 +1,308 @@
+package kinnosuke
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"filippo.io/age"
+
+	"kintai/internal/auth"
+)
+
+// sessionKeyName はセッションキャッシュを暗号化する age の秘密鍵を保存するシークレットの名前。
+// 初回の保存時に生成してシークレットストア（auth.SecretStore）に保存する。
+const sessionKeyName = "KN_SESSION_KEY"
+
+// sessionFileFromEnv はセッションキャッシュのパスを返す。
+// KN_SESSION_CACHE が off / false / 0 なら空（キャッシュしない）。
+// KN_SESSION_FILE が無ければ $XDG_CACHE_HOME/kintai/session-<プロファイル>.age。
+func sessionFileFromEnv() string {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("KN_SESSION_CACHE"))) {
+	case "off", "false", "0":
+		return ""
+	}
+	if p := strings.TrimSpace(os.Getenv("KN_SESSION_FILE")); p != "" {
+		return p
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	profile := strings.TrimSpace(os.Getenv("KN_PROFILE"))
+	if profile == "" {
+		profile = "default"
+	}
+	return filepath.Join(dir, "kintai", "session-"+profile+".age")
+}
+
+// savedCookie はセッションキャッシュに保存する Cookie 1つ分。
+type savedCookie struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	// Domain は Domain 属性。空ならホストだけに送る Cookie。
+	Domain   string    `json:"domain,omitempty"`
+	Path     string    `json:"path,omitempty"`
+	Expires  time.Time `json:"expires,omitempty"`
+	Secure   bool      `json:"secure,omitempty"`
+	HttpOnly bool      `json:"http_only,omitempty"`
+}
+
+// errSessionKeyPlaintext は鍵の保存先が平文の .env しかないため、セッションキャッシュを使わないことを表す。
+var errSessionKeyPlaintext = errors.New("session cache disabled: the secret store is plaintext .env")
+
+// persistentJar は勤之助のホストの Cookie を age で暗号化したファイルにも保存する http.CookieJar。
+// Cookie はメモリ上で更新し、Client.Close でまとめて書き出す。
+// 保存・読み込みに失敗してもエラーにはせず、メモリ上の Cookie だけで動く（毎回ログインするだけ）。
+type persistentJar struct {
+	inner *cookiejar.Jar
+	base  *url.URL
+	path  string
+	// store は鍵の保存先。nil なら auth.SecretStoreFromEnv で開く。
+	store auth.SecretStore
+
+	mu sync.Mutex
+	// cookies は cookieKey（名前・ドメイン・パス）→ Cookie。net/http/cookiejar と同じく、この3つが同じものを同じ Cookie とする。
+	cookies map[string]savedCookie
+	// dirty は読み込んでから Cookie が変わったか。
+	dirty bool
+	// rcpt は暗号化の鍵。読み込み時か最初の保存時に一度だけ決める。
+	rcpt    age.Recipient
+	rcptErr error
+}
+
+func newPersistentJar(base *url.URL, path string, store auth.SecretStore) (*persistentJar, error) {
+	inner, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	j := &persistentJar{inner: inner, base: base, path: path, store: store, cookies: map[string]savedCookie{}}
+	j.load()
+	return j, nil
+}
+
+func (j *persistentJar) Cookies(u *url.URL) []*http.Cookie { return j.inner.Cookies(u) }
+
+func (j *persistentJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.inner.SetCookies(u, cookies)
+	if !strings.EqualFold(u.Host, j.base.Host) {
+		return
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now()
+	for _, c := range cookies {
+		expires := c.Expires
+		if c.MaxAge > 0 {
+			expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+		}
+		path := c.Path
+		if path == "" || path[0] != '/' {
+			path = defaultCookiePath(u)
+		}
+		key := cookieKey(u, c.Name, c.Domain, path)
+		if c.MaxAge < 0 || (!expires.IsZero() && expires.Before(now)) {
+			delete(j.cookies, key)
+			continue
+		}
+		j.cookies[key] = savedCookie{
+			Name: c.Name, Value: c.Value, Domain: c.Domain, Path: path, Expires: expires,
+			Secure: c.Secure, HttpOnly: c.HttpOnly,
+		}
+	}
+	j.dirty = true
+}
+
+// cookieKey は u で受けた Cookie の、名前・ドメイン・パスの組を返す。
+// Domain 属性が無ければ u のホストだけの Cookie として扱う（net/http/cookiejar と同じ）。
+func cookieKey(u *url.URL, name, domain, path string) string {
+	domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+	if domain == "" {
+		domain = strings.ToLower(u.Hostname())
+	}
+	return domain + ";" + path + ";" + name
+}
+
+// defaultCookiePath は Path 属性の無い Cookie のパス（RFC 6265 5.1.4 の default-path）を返す。
+func defaultCookiePath(u *url.URL) string {
+	p := u.Path
+	if p == "" || p[0] != '/' {
+		return "/"
+	}
+	i := strings.LastIndex(p, "/")
+	if i == 0 {
+		return "/"
+	}
+	return p[:i]
+}
+
+// load はキャッシュを読んで inner に戻す。鍵が無い・復号できない場合は捨てる。
+func (j *persistentJar) load() {
+	b, err := os.ReadFile(j.path)
+	if err != nil {
+		return
+	}
+	key, err := j.lookupKey()
+	if err != nil || key == "" {
+		return
+	}
+	id, err := age.ParseX25519Identity(key)
+	if err != nil {
+		return
+	}
+	j.rcpt = id.Recipient()
+	r, err := age.Decrypt(bytes.NewReader(b), id)
+	if err != nil {
+		_ = os.Remove(j.path)
+		return
+	}
+	plain, err := io.ReadAll(r)
+	if err != nil {
+		return
+	}
+	var saved []savedCookie
+	if err := json.Unmarshal(plain, &saved); err != nil {
+		return
+	}
+
+	now := time.Now()
+	var cookies []*http.Cookie
+	for _, c := range saved {
+		if !c.Expires.IsZero() && c.Expires.Before(now) {
+			continue
+		}
+		if c.Path == "" {
+			c.Path = defaultCookiePath(j.base)
+		}
+		j.cookies[cookieKey(j.base, c.Name, c.Domain, c.Path)] = c
+		cookies = append(cookies, &http.Cookie{
+			Name: c.Name, Value: c.Value, Domain: c.Domain, Path: c.Path, Expires: c.Expires,
+			Secure: c.Secure, HttpOnly: c.HttpOnly,
+		})
+	}
+	j.inner.SetCookies(j.base, cookies)
+}
+
+// save は Cookie が変わっていれば暗号化して 0600 で書き出す。Cookie が無ければファイルを消す。
+func (j *persistentJar) save() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if !j.dirty {
+		return nil
+	}
+	if len(j.cookies) == 0 {
+		return removeSession(j.path)
+	}
+	if j.rcpt == nil && j.rcptErr == nil {
+		j.rcpt, j.rcptErr = j.recipient()
+	}
+	if j.rcptErr != nil {
+		return j.rcptErr
+	}
+	saved := make([]savedCookie, 0, len(j.cookies))
+	for _, c := range j.cookies {
+		saved = append(saved, c)
+	}
+	plain, err := json.Marshal(saved)
+	if err != nil {
+		return err
+	}
+	if err := auth.WriteEncryptedFile(j.path, plain, j.rcpt); err != nil {
+		return err
+	}
+	j.dirty = false
+	return nil
+}
+
+// secretStore は鍵の保存先を返す。
+func (j *persistentJar) secretStore() (auth.SecretStore, error) {
+	if j.store != nil {
+		return j.store, nil
+	}
+	return auth.SecretStoreFromEnv()
+}
+
+// lookupKey は鍵を環境変数 KN_SESSION_KEY から、無ければシークレットストアから読む。どちらにも無ければ空。
+func (j *persistentJar) lookupKey() (string, error) {
+	if v := strings.TrimSpace(os.Getenv(sessionKeyName)); v != "" {
+		return v, nil
+	}
+	store, err := j.secretStore()
+	if err != nil {
+		return "", err
+	}
+	v, err := store.Get(sessionKeyName)
+	if errors.Is(err, auth.ErrSecretNotFound) {
+		return "", nil
+	}
+	return v, err
+}
+
+// recipient はキャッシュの暗号化に使う鍵を返す。まだ無ければ生成してシークレットストアに保存する。
+// 鍵の保存先が平文の .env しかない場合は、鍵を書き出さずに errSessionKeyPlaintext を返す（キャッシュしない）。
+func (j *persistentJar) recipient() (age.Recipient, error) {
+	key, err := j.lookupKey()
+	if err != nil {
+		return nil, err
+	}
+	if key != "" {
+		id, err := age.ParseX25519Identity(key)
+		if err != nil {
+			return nil, err
+		}
+		return id.Recipient(), nil
+	}
+
+	store, err := j.secretStore()
+	if err != nil {
+		return nil, err
+	}
+	if auth.Plaintext(store) {
+		return nil, errSessionKeyPlaintext
+	}
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		return nil, err
+	}
+	if err := store.Set(sessionKeyName, id.String()); err != nil {
+		return nil, err
+	}
+	return id.Recipient(), nil
+}
+
+func removeSession(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Logout は勤之助からログアウトし、セッションのキャッシュを削除する。
+// キャッシュがあったかを返す。ログアウトの GET に失敗してもキャッシュは削除する。
+func Logout(ctx context.Context) (bool, error) {
+	opts := OptionsFromEnv()
+	if opts.SessionFile == "" {
+		return false, nil
+	}
+	if _, err := os.Stat(opts.SessionFile); errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+
+	cli, err := NewWithOptions(opts)
+	if err == nil {
+		_, _ = cli.GetHTML(ctx, url.Values{"module": {"logout"}})
+	}
+	return true, removeSession(opts.SessionFile)
+}
@@ -0,0 +1,253 @@
+package kinnosuke
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+
+	"kintai/internal/auth"
+)
+
+// enableSessionCache は t 専用の一時ファイルと鍵でセッションキャッシュを有効にし、そのパスを返す。
+func enableSessionCache(t *testing.T) string {
+	t.Helper()
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "kintai", "session.age")
+	t.Setenv("KN_SESSION_CACHE", "")
+	t.Setenv("KN_SESSION_FILE", path)
+	t.Setenv("KN_SESSION_KEY", id.String())
+	return path
+}
+
+func TestSessionCacheReused(t *testing.T) {
+	fs, _ := newFakeServer(t)
+	path := enableSessionCache(t)
+
+	for i := 0; i < 2; i++ {
+		if _, err := Today(t.Context()); err != nil {
+			t.Fatalf("Today #%d: %v", i+1, err)
+		}
+	}
+	if fs.logins != 1 {
+		t.Errorf("logins = %d, want 1 (session should be reused)", fs.logins)
+	}
+
+	st, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := st.Mode().Perm(); perm != 0600 {
+		t.Errorf("perm = %o, want 600", perm)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), sessionCookie) {
+		t.Error("session file is not encrypted")
+	}
+}
+
+func TestSessionCacheWrongKey(t *testing.T) {
+	fs, _ := newFakeServer(t)
+	enableSessionCache(t)
+	if _, err := Today(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	// 鍵が変わったら復号できないキャッシュは捨てて、ログインし直す
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("KN_SESSION_KEY", id.String())
+	if _, err := Today(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if fs.logins != 2 {
+		t.Errorf("logins = %d, want 2", fs.logins)
+	}
+}
+
+func TestLogout(t *testing.T) {
+	fs, _ := newFakeServer(t)
+	path := enableSessionCache(t)
+
+	if ok, err := Logout(t.Context()); err != nil || ok {
+		t.Fatalf("Logout without cache = %v, %v; want false, nil", ok, err)
+	}
+
+	if _, err := Today(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	ok, err := Logout(t.Context())
+	if err != nil || !ok {
+		t.Fatalf("Logout = %v, %v; want true, nil", ok, err)
+	}
+	if fs.logouts != 1 {
+		t.Errorf("logouts = %d, want 1", fs.logouts)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("session file still exists: %v", err)
+	}
+
+	if _, err := Today(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if fs.logins != 2 {
+		t.Errorf("logins = %d, want 2 (should log in again after logout)", fs.logins)
+	}
+}
+
+// memStore はメモリ上の auth.SecretStore。
+type memStore map[string]string
+
+func (s memStore) Name() string { return "memory" }
+
+func (s memStore) Get(key string) (string, error) {
+	v, ok := s[key]
+	if !ok {
+		return "", auth.ErrSecretNotFound
+	}
+	return v, nil
+}
+
+func (s memStore) Set(key, value string) error { s[key] = value; return nil }
+
+func (s memStore) Delete(key string) error { delete(s, key); return nil }
+
+// loginWithStore は store を鍵の保存先にした Client でログインし、Close でキャッシュを保存した結果を返す。
+func loginWithStore(t *testing.T, path string, store auth.SecretStore) error {
+	t.Helper()
+	opts := OptionsFromEnv()
+	opts.SessionFile = path
+	opts.SecretStore = store
+	cli, err := NewWithOptions(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cred, err := loadCredentialFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ensureAuthorized(t.Context(), cli, cred); err != nil {
+		t.Fatal(err)
+	}
+	return cli.Close()
+}
+
+func TestSessionCacheGeneratesKey(t *testing.T) {
+	fs, _ := newFakeServer(t)
+	path := filepath.Join(t.TempDir(), "session.age")
+	t.Setenv("KN_SESSION_KEY", "")
+	store := memStore{}
+
+	for i := 0; i < 2; i++ {
+		if err := loginWithStore(t, path, store); err != nil {
+			t.Fatalf("Close #%d: %v", i+1, err)
+		}
+	}
+	if fs.logins != 1 {
+		t.Errorf("logins = %d, want 1 (session should be reused)", fs.logins)
+	}
+	// 初回の保存で生成した鍵はシークレットストアに入る
+	if _, err := age.ParseX25519Identity(store[sessionKeyName]); err != nil {
+		t.Errorf("stored %s is invalid: %v", sessionKeyName, err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("session file not written: %v", err)
+	}
+}
+
+func TestSessionCachePlaintextStore(t *testing.T) {
+	fs, _ := newFakeServer(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "session.age")
+	envPath := filepath.Join(dir, ".env")
+	t.Setenv("KN_SESSION_KEY", "")
+	store, err := auth.OpenSecretStore(auth.SecretStoreOptions{Backend: auth.BackendDotenv, EnvPath: envPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 鍵の保存先が平文の .env しかないなら、鍵を作らずキャッシュもしない
+	for i := 0; i < 2; i++ {
+		if err := loginWithStore(t, path, store); !errors.Is(err, errSessionKeyPlaintext) {
+			t.Fatalf("Close #%d = %v, want errSessionKeyPlaintext", i+1, err)
+		}
+	}
+	if fs.logins != 2 {
+		t.Errorf("logins = %d, want 2 (session must not be cached)", fs.logins)
+	}
+	for _, p := range []string{path, envPath} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("%s must not be written: %v", p, err)
+		}
+	}
+}
+
+func TestValidateEnvKeepsSessionCache(t *testing.T) {
+	newFakeServer(t)
+	path := enableSessionCache(t)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	// 復号できないキャッシュでも、検証だけなら読まない（消さない）
+	if err := os.WriteFile(path, []byte("not age"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateEnv(); err != nil {
+		t.Fatalf("ValidateEnv: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("session file removed by ValidateEnv: %v", err)
+	}
+}
+
+func TestPersistentJarKeysByNameDomainPath(t *testing.T) {
+	path := enableSessionCache(t)
+	base, _ := url.Parse("https://kinnosuke.example.com/")
+	sub, _ := url.Parse("https://kinnosuke.example.com/app/")
+	j, err := newPersistentJar(base, path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 名前が同じでもパスやドメインが違えば別の Cookie
+	j.SetCookies(base, []*http.Cookie{
+		{Name: "sid", Value: "root", Path: "/"},
+		{Name: "sid", Value: "app", Path: "/app/"},
+		{Name: "sid", Value: "domain", Domain: "example.com", Path: "/"},
+	})
+	// 片方を消しても、もう片方は残る
+	j.SetCookies(base, []*http.Cookie{{Name: "sid", Path: "/", MaxAge: -1}})
+	if err := j.save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	j2, err := newPersistentJar(base, path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(j2.cookies) != 2 {
+		t.Errorf("cookies = %v, want 2 (app, domain)", j2.cookies)
+	}
+	var got []string
+	for _, c := range j2.Cookies(sub) {
+		got = append(got, c.Value)
+	}
+	sort.Strings(got)
+	if want := []string{"app", "domain"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Cookies(/app/) = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,351 @@
+package kinnosuke
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"kintai/internal/config"
+)
+
+func TestEnsureAuthorizedLogsIn(t *testing.T) {
+	fs, _ := newFakeServer(t)
+
+	cli, err := NewWithOptions(OptionsFromEnv())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cred, err := loadCredentialFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	top, err := ensureAuthorized(t.Context(), cli, cred)
+	if err != nil {
+		t.Fatalf("ensureAuthorized: %v", err)
+	}
+	if !top.Authorized() {
+		t.Error("top page is not authorized")
+	}
+	if fs.logins != 1 {
+		t.Errorf("logins = %d, want 1", fs.logins)
+	}
+
+	// セッションが生きていれば再ログインしない
+	if _, err := ensureAuthorized(t.Context(), cli, cred); err != nil {
+		t.Fatalf("ensureAuthorized (2nd): %v", err)
+	}
+	if fs.logins != 1 {
+		t.Errorf("logins = %d after 2nd call, want 1", fs.logins)
+	}
+}
+
+func TestEnsureAuthorizedBadCredential(t *testing.T) {
+	newFakeServer(t)
+	t.Setenv("KIN_PASSWORD", "wrong")
+
+	cli, err := NewWithOptions(OptionsFromEnv())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cred, err := loadCredentialFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ensureAuthorized(t.Context(), cli, cred); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("err = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestStampStartAndEnd(t *testing.T) {
+	fs, _ := newFakeServer(t)
+
+	got, err := StampStart(t.Context(), StampOptions{})
+	if err != nil {
+		t.Fatalf("StampStart: %v", err)
+	}
+	if got != "09:00" {
+		t.Errorf("StampStart = %q, want 09:00", got)
+	}
+
+	got, err = StampEnd(t.Context(), StampOptions{})
+	if err != nil {
+		t.Fatalf("StampEnd: %v", err)
+	}
+	if got != "18:30" {
+		t.Errorf("StampEnd = %q, want 18:30", got)
+	}
+
+	if strings.Join(fs.stamps, ",") != "1,2" {
+		t.Errorf("stamps = %v, want [1 2]", fs.stamps)
+	}
+}
+
+func TestStampNotConfirmed(t *testing.T) {
+	fs, _ := newFakeServer(t)
+	fs.noStamp = true
+
+	if _, err := StampStart(t.Context(), StampOptions{}); !errors.Is(err, ErrStampNotConfirmed) {
+		t.Fatalf("err = %v, want ErrStampNotConfirmed", err)
+	}
+}
+
+func TestStampPostFailure(t *testing.T) {
+	fs, _ := newFakeServer(t)
+	fs.failPost = true
+
+	_, err := StampStart(t.Context(), StampOptions{})
+	if err == nil || !strings.Contains(err.Error(), "stamp failed") {
+		t.Fatalf("err = %v, want stamp failed", err)
+	}
+	// 打刻のPOSTは届いた可能性があるので再試行しない
+	if fs.stampPosts != 1 {
+		t.Errorf("stampPosts = %d, want 1", fs.stampPosts)
+	}
+}
+
+func TestStampResponseLost(t *testing.T) {
+	fs, _ := newFakeServer(t)
+	fs.failAfterStamp = true
+
+	// POST の応答は失敗でも、トップページで打刻を確認できれば成功とする
+	got, err := StampStart(t.Context(), StampOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "09:00" {
+		t.Errorf("time = %q, want 09:00", got)
+	}
+	if fs.stampPosts != 1 {
+		t.Errorf("stampPosts = %d, want 1", fs.stampPosts)
+	}
+}
+
+func TestRetryTransientGet(t *testing.T) {
+	fs, _ := newFakeServer(t)
+	fs.failGets = 2
+
+	if _, err := Today(t.Context()); err != nil {
+		t.Fatalf("Today: %v", err)
+	}
+
+	t.Setenv("KN_RETRY_MAX_ATTEMPTS", "2")
+	fs.failGets = 2
+	_, err := Today(t.Context())
+	var se *ErrHTTPStatus
+	if !errors.As(err, &se) || se.Code != http.StatusServiceUnavailable {
+		t.Fatalf("err = %v, want 503 ErrHTTPStatus", err)
+	}
+}
+
+func TestStampMissingCredential(t *testing.T) {
+	newFakeServer(t)
+	t.Setenv("KIN_PASSWORD", "")
+
+	if _, err := StampStart(t.Context(), StampOptions{}); !errors.Is(err, config.ErrMissing) {
+		t.Fatalf("err = %v, want config.ErrMissing", err)
+	}
+}
+
+func TestToday(t *testing.T) {
+	fs, _ := newFakeServer(t)
+	fs.started = true
+
+	page, err := Today(t.Context())
+	if err != nil {
+		t.Fatalf("Today: %v", err)
+	}
+	if page.UserName != "山田 太郎" || page.StartTime != "09:00" || page.LeaveTime != "" {
+		t.Errorf("Today = %+v", page)
+	}
+	if len(fs.stamps) != 0 {
+		t.Errorf("Today must not stamp: stamps = %v", fs.stamps)
+	}
+}
+
+func TestStampRefusesDoubleStamp(t *testing.T) {
+	fs, _ := newFakeServer(t)
+	fs.started = true
+
+	_, err := StampStart(t.Context(), StampOptions{})
+	var already *ErrAlreadyStamped
+	if !errors.As(err, &already) {
+		t.Fatalf("err = %v, want *ErrAlreadyStamped", err)
+	}
+	if already.Label != "出社" || already.Time != "09:00" {
+		t.Errorf("ErrAlreadyStamped = %+v", already)
+	}
+	if len(fs.stamps) != 0 {
+		t.Errorf("stamps = %v, want none", fs.stamps)
+	}
+
+	// --force なら既存の打刻があっても打刻する
+	if _, err := StampStart(t.Context(), StampOptions{Force: true}); err != nil {
+		t.Fatalf("StampStart(force): %v", err)
+	}
+	if strings.Join(fs.stamps, ",") != "1" {
+		t.Errorf("stamps = %v, want [1]", fs.stamps)
+	}
+}
+
+func TestStampStartWithNote(t *testing.T) {
+	fs, _ := newFakeServer(t)
+
+	if _, err := StampStart(t.Context(), StampOptions{Note: "客先直行"}); err != nil {
+		t.Fatalf("StampStart: %v", err)
+	}
+	if len(fs.notes) != 1 || fs.notes[0] != "客先直行" {
+		t.Errorf("notes = %v, want [客先直行]", fs.notes)
+	}
+}
+
+func TestStampBreak(t *testing.T) {
+	fs, _ := newFakeServer(t)
+	fs.started = true
+	fs.extraButtons = []string{"break_buttons_unverified.html"}
+
+	got, err := StampBreakStart(t.Context(), StampOptions{})
+	if err != nil {
+		t.Fatalf("StampBreakStart: %v", err)
+	}
+	if got != "12:00" {
+		t.Errorf("StampBreakStart = %q, want 12:00", got)
+	}
+
+	got, err = StampBreakEnd(t.Context(), StampOptions{})
+	if err != nil {
+		t.Fatalf("StampBreakEnd: %v", err)
+	}
+	if got != "13:00" {
+		t.Errorf("StampBreakEnd = %q, want 13:00", got)
+	}
+
+	page, err := Today(t.Context())
+	if err != nil {
+		t.Fatalf("Today: %v", err)
+	}
+	if page.BreakStartTime != "12:00" || page.BreakEndTime != "13:00" {
+		t.Errorf("Today = %+v", page)
+	}
+
+	// 休憩は1日に何度でも取れるので、2回目も打刻済みとして拒否しない
+	got, err = StampBreakStart(t.Context(), StampOptions{})
+	if err != nil {
+		t.Fatalf("second StampBreakStart: %v", err)
+	}
+	if got != "12:10" {
+		t.Errorf("second StampBreakStart = %q, want 12:10 (not the earlier 12:00)", got)
+	}
+	// 打刻種別はトップページのボタンから読み取ったもの
+	if strings.Join(fs.stamps, ",") != "13,14,13" {
+		t.Errorf("stamps = %v, want [13 14 13]", fs.stamps)
+	}
+}
+
+func TestStampBreakButtonNotFound(t *testing.T) {
+	fs, _ := newFakeServer(t)
+	fs.started = true
+
+	// 休憩のボタンが無い画面では、種別を推測して打刻しない
+	if _, err := StampBreakStart(t.Context(), StampOptions{}); !errors.Is(err, ErrStampButtonNotFound) {
+		t.Fatalf("StampBreakStart err = %v, want ErrStampButtonNotFound", err)
+	}
+	if fs.stampPosts != 0 {
+		t.Errorf("stampPosts = %d, want 0", fs.stampPosts)
+	}
+}
+
+func TestStampRepeatedNotConfirmed(t *testing.T) {
+	fs, _ := newFakeServer(t)
+	fs.started = true
+	fs.extraButtons = []string{"out_buttons_unverified.html"}
+	if _, err := StampOut(t.Context(), StampOptions{}); err != nil {
+		t.Fatalf("StampOut: %v", err)
+	}
+
+	// 2回目の打刻が反映されなければ、前回の時刻を今回の打刻時刻として返さない
+	fs.noStamp = true
+	if got, err := StampOut(t.Context(), StampOptions{}); !errors.Is(err, ErrStampNotConfirmed) {
+		t.Errorf("StampOut = %q, %v; want ErrStampNotConfirmed", got, err)
+	}
+}
+
+func TestStampOutAndBack(t *testing.T) {
+	fs, _ := newFakeServer(t)
+	fs.started = true
+	fs.extraButtons = []string{"out_buttons_unverified.html"}
+
+	got, err := StampOut(t.Context(), StampOptions{})
+	if err != nil {
+		t.Fatalf("StampOut: %v", err)
+	}
+	if got != "14:00" {
+		t.Errorf("StampOut = %q, want 14:00", got)
+	}
+
+	got, err = StampBack(t.Context(), StampOptions{})
+	if err != nil {
+		t.Fatalf("StampBack: %v", err)
+	}
+	if got != "16:30" {
+		t.Errorf("StampBack = %q, want 16:30", got)
+	}
+
+	// 2回目の外出・再入も打刻する
+	if got, err = StampOut(t.Context(), StampOptions{}); err != nil || got != "14:10" {
+		t.Errorf("second StampOut = %q, %v; want 14:10", got, err)
+	}
+	if got, err = StampBack(t.Context(), StampOptions{}); err != nil || got != "16:40" {
+		t.Errorf("second StampBack = %q, %v; want 16:40", got, err)
+	}
+	// 打刻種別はトップページのボタンから読み取ったもの
+	if strings.Join(fs.stamps, ",") != "15,16,15,16" {
+		t.Errorf("stamps = %v, want [15 16 15 16]", fs.stamps)
+	}
+}
+
+func TestStampOutButtonNotFound(t *testing.T) {
+	fs, _ := newFakeServer(t)
+	fs.started = true
+	fs.extraButtons = []string{"break_buttons_unverified.html"}
+
+	// 外出のボタンが無い画面では、種別を推測して打刻しない
+	if _, err := StampOut(t.Context(), StampOptions{}); !errors.Is(err, ErrStampButtonNotFound) {
+		t.Fatalf("StampOut err = %v, want ErrStampButtonNotFound", err)
+	}
+	if fs.stampPosts != 0 {
+		t.Errorf("stampPosts = %d, want 0", fs.stampPosts)
+	}
+}
+
+func TestContextCanceled(t *testing.T) {
+	fs, _ := newFakeServer(t)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if _, err := StampStart(ctx, StampOptions{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if fs.logins != 0 || fs.stampPosts != 0 {
+		t.Errorf("logins = %d, stampPosts = %d; want no requests", fs.logins, fs.stampPosts)
+	}
+}
+
+func TestContextDeadline(t *testing.T) {
+	fs, _ := newFakeServer(t)
+	fs.delay = 5 * time.Second
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := Today(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	// 期限切れは再試行せず、待たずに返る
+	if d := time.Since(start); d > 2*time.Second {
+		t.Errorf("Today took %v", d)
+	}
+}
@@ -0,0 +1,290 @@
+package kinnosuke
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// 月次勤怠（出勤簿）ページのクエリ。Web画面の「月次勤怠」リンクと同じ。
+const (
+	timesheetModule = "timesheet"
+	timesheetAction = "browse"
+)
+
+var (
+	// "10/01" / "10/01(木)" 形式の日付セル
+	reTimesheetDate = regexp.MustCompile(`^(\d{1,2})/(\d{1,2})`)
+	// "1:00" / "12:30" 形式の時間（休憩・残業）
+	reDuration = regexp.MustCompile(`^(\d+):(\d\d)$`)
+)
+
+// 月次勤怠の列見出し。列の順番はテナントの設定で変わるので見出しで対応付ける。
+const (
+	colDate      = "日付"
+	colLeaveType = "休暇区分"
+	colStart     = "出社"
+	colLeave     = "退社"
+	colBreak     = "休憩"
+	colOvertime  = "残業"
+	colApproval  = "承認状況"
+	colNote      = "備考"
+)
+
+// DayRecord は月次勤怠の1日分。
+type DayRecord struct {
+	// Date はその日の 0:00（Asia/Tokyo）。
+	Date time.Time
+	// Start / Leave は出社・退社時刻（"09:00" 形式）。未打刻なら空。
+	Start string
+	Leave string
+	// Break / Overtime は休憩時間・残業時間。
+	Break    time.Duration
+	Overtime time.Duration
+	// LeaveType は休暇区分（有休・午前半休など）。休暇でなければ空。
+	LeaveType string
+	// Approval は承認状況（承認済・申請中など）。
+	Approval string
+	// Note は備考。
+	Note string
+	// Holiday は所定休日（土日・祝日など）か。行の class（saturday / sunday / holiday）で判定する。
+	Holiday bool
+}
+
+// FullDayLeave は休暇区分が全休と読めるかを返す。承認状況は見ない。
+// 月次勤怠の休暇区分は取得単位を含んで表示されるので（有休・午前半休など）、休暇の種類の表示名だけのときか、
+// 「全日」と明記されているときだけ true。午前・午後・半休・時間休と読めるものや、読み取れないものは全休としない。
+func (r DayRecord) FullDayLeave() bool {
+	t := strings.TrimSpace(r.LeaveType)
+	if u := parseLeaveUnit(t); u == HalfAM || u == HalfPM || strings.Contains(t, "半") || strings.Contains(t, "時間") {
+		return false
+	}
+	if strings.Contains(t, halfDays[HalfNone].Label) {
+		return true
+	}
+	for _, lt := range LeaveTypes {
+		if t == lt.Label {
+			return true
+		}
+	}
+	return false
+}
+
+// Timesheet はログインして month（年月のみ使う）の月次勤怠を取得する。打刻はしない。
+func Timesheet(ctx context.Context, month time.Time) ([]DayRecord, error) {
+	cli, err := NewWithOptions(OptionsFromEnv())
+	if err != nil {
+		return nil, err
+	}
+	defer cli.Close()
+	return cli.Timesheet(ctx, month)
+}
+
+// Timesheet は KIN_* の認証情報でログインし、month の月次勤怠ページを読み取る。
+func (c *Client) Timesheet(ctx context.Context, month time.Time) ([]DayRecord, error) {
+	cred, err := loadCredentialFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ensureAuthorized(ctx, c, cred); err != nil {
+		return nil, err
+	}
+
+	src, err := c.GetHTML(ctx, url.Values{
+		"module": {timesheetModule},
+		"action": {timesheetAction},
+		"year":   {strconv.Itoa(month.Year())},
+		"month":  {strconv.Itoa(int(month.Month()))},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !authorized(src) {
+		return nil, fmt.Errorf("%w: session expired while reading timesheet", ErrUnauthorized)
+	}
+	return ParseTimesheet(src, month)
+}
+
+// ParseTimesheet は月次勤怠ページの HTML から日ごとの記録を読み取る。
+// 日付セルには年が無いので month の年を補う（締め日で年をまたぐ場合も考慮する）。
+func ParseTimesheet(src string, month time.Time) ([]DayRecord, error) {
+	rows, ok := tableRows(src, "timesheet_table")
+	if !ok {
+		return nil, ErrTimesheetNotFound
+	}
+
+	var (
+		cols    map[string]int
+		records []DayRecord
+	)
+	loc := jst()
+	for i, row := range rows {
+		if cols == nil {
+			if row.header {
+				cols = columnIndex(row.cells)
+			}
+			continue
+		}
+		cell := func(name string) string {
+			if j, ok := cols[name]; ok && j < len(row.cells) {
+				return row.cells[j]
+			}
+			return ""
+		}
+
+		// 合計行など、日付でない行は読み飛ばす
+		m := reTimesheetDate.FindStringSubmatch(cell(colDate))
+		if m == nil {
+			continue
+		}
+		mon, _ := strconv.Atoi(m[1])
+		day, _ := strconv.Atoi(m[2])
+		year := month.Year()
+		switch {
+		case mon == 12 && month.Month() == time.January:
+			year--
+		case mon == 1 && month.Month() == time.December:
+			year++
+		}
+
+		r := DayRecord{
+			Date:      time.Date(year, time.Month(mon), day, 0, 0, 0, 0, loc),
+			Start:     cell(colStart),
+			Leave:     cell(colLeave),
+			LeaveType: cell(colLeaveType),
+			Approval:  cell(colApproval),
+			Note:      cell(colNote),
+			Holiday:   row.holiday,
+		}
+		var err error
+		if r.Break, err = parseDuration(cell(colBreak)); err != nil {
+			return nil, fmt.Errorf("timesheet row %d: %s: %w", i, colBreak, err)
+		}
+		if r.Overtime, err = parseDuration(cell(colOvertime)); err != nil {
+			return nil, fmt.Errorf("timesheet row %d: %s: %w", i, colOvertime, err)
+		}
+		records = append(records, r)
+	}
+	if cols == nil {
+		return nil, fmt.Errorf("%w: header row not found", ErrTimesheetNotFound)
+	}
+	if _, ok := cols[colDate]; !ok {
+		return nil, fmt.Errorf("%w: %s column not found", ErrTimesheetNotFound, colDate)
+	}
+	return records, nil
+}
+
+// parseDuration は "1:30" 形式を time.Duration にする。空なら 0。
+func parseDuration(s string) (time.Duration, error) {
+	if s == "" || s == "-" {
+		return 0, nil
+	}
+	m := reDuration.FindStringSubmatch(s)
+	if m == nil {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	h, _ := strconv.Atoi(m[1])
+	min, _ := strconv.Atoi(m[2])
+	return time.Duration(h)*time.Hour + time.Duration(min)*time.Minute, nil
+}
+
+func columnIndex(headers []string) map[string]int {
+	cols := make(map[string]int, len(headers))
+	for i, h := range headers {
+		if _, dup := cols[h]; !dup {
+			cols[h] = i
+		}
+	}
+	return cols
+}
+
+type tableRow struct {
+	header  bool // th だけの行
+	holiday bool // 所定休日の行（月次勤怠のみ）
+	cells   []string
+}
+
+// holidayClasses は所定休日の行に付く class。
+var holidayClasses = []string{"saturday", "sunday", "holiday"}
+
+// tableRows は class 属性に class を持つ表の行を、セルのテキストの並びとして返す。
+// 表が無ければ ok=false。
+func tableRows(src, class string) (rows []tableRow, ok bool) {
+	z := html.NewTokenizer(strings.NewReader(src))
+
+	var (
+		inTable int // 表のネスト深さ（0なら外）
+		row     *tableRow
+		inCell  bool
+		text    strings.Builder
+	)
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			return rows, ok
+
+		case html.StartTagToken:
+			tok := z.Token()
+			switch {
+			case inTable == 0:
+				if tok.Data == "table" && hasClass(tok, class) {
+					inTable = 1
+					ok = true
+				}
+			case tok.Data == "table":
+				inTable++
+			case inTable == 1 && tok.Data == "tr":
+				row = &tableRow{header: true}
+				for _, c := range holidayClasses {
+					row.holiday = row.holiday || hasClass(tok, c)
+				}
+			case inTable == 1 && row != nil && (tok.Data == "td" || tok.Data == "th"):
+				if tok.Data == "td" {
+					row.header = false
+				}
+				inCell = true
+				text.Reset()
+			}
+
+		case html.EndTagToken:
+			tok := z.Token()
+			switch {
+			case inTable == 0:
+			case tok.Data == "table":
+				inTable--
+			case inTable == 1 && inCell && (tok.Data == "td" || tok.Data == "th"):
+				row.cells = append(row.cells, strings.TrimSpace(text.String()))
+				inCell = false
+			case inTable == 1 && row != nil && tok.Data == "tr":
+				if len(row.cells) > 0 {
+					rows = append(rows, *row)
+				}
+				row = nil
+			}
+
+		case html.TextToken:
+			if inCell {
+				text.Write(z.Text())
+			}
+		}
+	}
+}
+
+var weekdaysJa = [...]string{"日", "月", "火", "水", "木", "金", "土"}
+
+// WeekdayJa は曜日の1文字の表記（"月" など）を返す。勤之助の画面の表記に合わせる。
+func WeekdayJa(d time.Weekday) string { return weekdaysJa[d] }
+
+func jst() *time.Location {
+	if loc, err := time.LoadLocation("Asia/Tokyo"); err == nil {
+		return loc
+	}
+	return time.FixedZone("JST", 9*60*60)
+}
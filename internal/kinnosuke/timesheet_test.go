@@ -0,0 +1,90 @@
+package kinnosuke
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseTimesheet(t *testing.T) {
+	month := time.Date(2026, time.October, 1, 0, 0, 0, 0, jst())
+	got, err := ParseTimesheet(readFixture(t, "timesheet.html"), month)
+	if err != nil {
+		t.Fatalf("ParseTimesheet: %v", err)
+	}
+
+	day := func(d int) time.Time { return time.Date(2026, time.October, d, 0, 0, 0, 0, jst()) }
+	want := []DayRecord{
+		{Date: day(1), Start: "09:00", Leave: "18:30", Break: time.Hour, Overtime: 30 * time.Minute, Approval: "承認済"},
+		{Date: day(2), LeaveType: "有休", Approval: "申請中", Note: "通院"},
+		{Date: day(3), Holiday: true},
+		{Date: day(5), Start: "13:05", Leave: "21:15", Break: 45 * time.Minute, Overtime: 2*time.Hour + 10*time.Minute,
+			LeaveType: "午前半休", Approval: "未承認", Note: "リリース対応"},
+		{Date: day(12), LeaveType: "祝日", Note: "スポーツの日", Holiday: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseTimesheet =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestDayRecordFullDayLeave(t *testing.T) {
+	for label, want := range map[string]bool{
+		"有休":     true,
+		"代休":     true,
+		"有休（全日）": true,
+		"午前半休":   false,
+		"午前休":    false,
+		"午後休":    false,
+		"半日有休":   false,
+		"時間休":    false,
+		"欠勤":     false, // 休暇の種類として読み取れない
+		"":       false,
+	} {
+		if got := (DayRecord{LeaveType: label}).FullDayLeave(); got != want {
+			t.Errorf("FullDayLeave(%q) = %v, want %v", label, got, want)
+		}
+	}
+}
+
+func TestParseTimesheetYearBoundary(t *testing.T) {
+	// 締め日の都合で前月の日付が混ざる場合は年を補正する
+	src := `<table class="timesheet_table"><tr><th>日付</th></tr>` +
+		`<tr><td>12/31(水)</td></tr><tr><td>01/01(木)</td></tr></table>`
+	got, err := ParseTimesheet(src, time.Date(2026, time.January, 1, 0, 0, 0, 0, jst()))
+	if err != nil {
+		t.Fatalf("ParseTimesheet: %v", err)
+	}
+	if len(got) != 2 || got[0].Date.Year() != 2025 || got[1].Date.Year() != 2026 {
+		t.Errorf("dates = %v", got)
+	}
+}
+
+func TestParseTimesheetNotFound(t *testing.T) {
+	for _, src := range []string{
+		readFixture(t, "before_stamp.html"),
+		`<table class="timesheet_table"><tr><td>10/01</td></tr></table>`,
+	} {
+		if _, err := ParseTimesheet(src, time.Now()); !errors.Is(err, ErrTimesheetNotFound) {
+			t.Errorf("err = %v, want ErrTimesheetNotFound", err)
+		}
+	}
+}
+
+func TestTimesheetLogsIn(t *testing.T) {
+	fs, _ := newFakeServer(t)
+
+	got, err := Timesheet(t.Context(), time.Date(2026, time.October, 15, 0, 0, 0, 0, jst()))
+	if err != nil {
+		t.Fatalf("Timesheet: %v", err)
+	}
+	if len(got) != 5 {
+		t.Errorf("len = %d, want 5", len(got))
+	}
+	if fs.logins != 1 {
+		t.Errorf("logins = %d, want 1", fs.logins)
+	}
+	if y, m := fs.timesheetQuery.Get("year"), fs.timesheetQuery.Get("month"); y != "2026" || m != "10" {
+		t.Errorf("query year=%q month=%q, want 2026/10", y, m)
+	}
+}
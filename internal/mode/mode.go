@@ -0,0 +1,141 @@
+package mode
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"kintai/internal/config"
+)
+
+var reName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Mode は出社種別（office / remote など）1つ分の定義。
+type Mode struct {
+	// Name は --mode に指定する名前。
+	Name string
+	// Alias は --mode に指定できる短縮名（o / r など）。空なら短縮名なし。
+	Alias string
+	// Description は補完候補やヘルプに出す説明。
+	Description string
+	// Emoji は Slack の開始スレに付ける絵文字名（コロンなし）。
+	Emoji string
+	// StampType は勤之助の timerecorder_stamping_type。空なら通常の出社（"1"）。
+	StampType string
+	// Note は勤之助の打刻に添える備考。空なら送らない。
+	Note string
+}
+
+// Builtin は設定ファイルが無くても使える組み込みの mode。
+func Builtin() []Mode {
+	return []Mode{
+		{Name: "office", Alias: "o", Description: "オフィス出社", Emoji: "shussha"},
+		{Name: "remote", Alias: "r", Description: "リモートワーク", Emoji: "remote-start"},
+	}
+}
+
+// Registry は使用可能な mode の一覧。名前・短縮名で引ける。
+type Registry struct {
+	modes []Mode
+	index map[string]int // 名前・短縮名 → modes の添字
+}
+
+// NewRegistry は modes から Registry を作る。
+// 同じ名前が複数あれば後のものが前のものを上書きする（組み込み → 設定ファイルの順に渡す想定）。
+func NewRegistry(modes ...Mode) (*Registry, error) {
+	byName := map[string]Mode{}
+	for _, m := range modes {
+		if !reName.MatchString(m.Name) {
+			return nil, fmt.Errorf("%w: mode name %q: 英小文字・数字・-・_ のみ使えます", config.ErrInvalid, m.Name)
+		}
+		if prev, ok := byName[m.Name]; ok {
+			m = merge(prev, m)
+		}
+		byName[m.Name] = m
+	}
+
+	r := &Registry{index: map[string]int{}}
+	names := make([]string, 0, len(byName))
+	for n := range byName {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	for _, n := range names {
+		m := byName[n]
+		if m.Emoji == "" {
+			return nil, fmt.Errorf("%w: mode %q: emoji is required", config.ErrInvalid, m.Name)
+		}
+		r.modes = append(r.modes, m)
+	}
+	for i, m := range r.modes {
+		r.index[m.Name] = i
+	}
+	for i, m := range r.modes {
+		if m.Alias == "" {
+			continue
+		}
+		if j, ok := r.index[m.Alias]; ok && j != i {
+			return nil, fmt.Errorf("%w: mode %q: alias %q conflicts with mode %q", config.ErrInvalid, m.Name, m.Alias, r.modes[j].Name)
+		}
+		r.index[m.Alias] = i
+	}
+	return r, nil
+}
+
+// merge は base を override の空でない項目で上書きする。
+func merge(base, override Mode) Mode {
+	if override.Alias != "" {
+		base.Alias = override.Alias
+	}
+	if override.Description != "" {
+		base.Description = override.Description
+	}
+	if override.Emoji != "" {
+		base.Emoji = override.Emoji
+	}
+	if override.StampType != "" {
+		base.StampType = override.StampType
+	}
+	if override.Note != "" {
+		base.Note = override.Note
+	}
+	return base
+}
+
+// Lookup は名前または短縮名から mode を返す。
+func (r *Registry) Lookup(nameOrAlias string) (Mode, bool) {
+	i, ok := r.index[nameOrAlias]
+	if !ok {
+		return Mode{}, false
+	}
+	return r.modes[i], true
+}
+
+// Modes は登録されている mode を名前順で返す。
+func (r *Registry) Modes() []Mode {
+	return append([]Mode(nil), r.modes...)
+}
+
+// Emojis は mode 名 → 絵文字名の対応を返す。
+func (r *Registry) Emojis() map[string]string {
+	m := make(map[string]string, len(r.modes))
+	for _, md := range r.modes {
+		m[md.Name] = md.Emoji
+	}
+	return m
+}
+
+// Usage は "office(o), remote(r), client-site" のような一覧を返す。
+func (r *Registry) Usage() string {
+	parts := make([]string, 0, len(r.modes))
+	for _, m := range r.modes {
+		if m.Alias != "" {
+			parts = append(parts, m.Name+"("+m.Alias+")")
+		} else {
+			parts = append(parts, m.Name)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,56 @@
+package mode
+
+import (
+	"errors"
+	"testing"
+
+	"kintai/internal/config"
+)
+
+func TestRegistry(t *testing.T) {
+	reg, err := NewRegistry(append(Builtin(),
+		Mode{Name: "remote", Emoji: "house"}, // 組み込みの絵文字だけ上書き
+		Mode{Name: "client-site", Alias: "c", Emoji: "office-client", Note: "客先"},
+	)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, ok := reg.Lookup("r")
+	if !ok || m.Name != "remote" || m.Emoji != "house" || m.Alias != "r" {
+		t.Errorf("Lookup(r) = %+v, %v", m, ok)
+	}
+	m, ok = reg.Lookup("client-site")
+	if !ok || m.Note != "客先" {
+		t.Errorf("Lookup(client-site) = %+v, %v", m, ok)
+	}
+	if _, ok := reg.Lookup("business-trip"); ok {
+		t.Error("Lookup(business-trip) should fail")
+	}
+
+	if got, want := reg.Usage(), "client-site(c), office(o), remote(r)"; got != want {
+		t.Errorf("Usage = %q, want %q", got, want)
+	}
+	if got := reg.Emojis(); got["office"] != "shussha" || got["client-site"] != "office-client" {
+		t.Errorf("Emojis = %v", got)
+	}
+}
+
+func TestRegistryInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		modes []Mode
+	}{
+		{"bad name", []Mode{{Name: "Client Site", Emoji: "x"}}},
+		{"no emoji", []Mode{{Name: "business-trip"}}},
+		{"alias conflict", append(Builtin(), Mode{Name: "onsite", Alias: "o", Emoji: "x"})},
+		{"alias shadows name", append(Builtin(), Mode{Name: "hybrid", Alias: "office", Emoji: "x"})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewRegistry(tt.modes...); !errors.Is(err, config.ErrInvalid) {
+				t.Errorf("err = %v, want config.ErrInvalid", err)
+			}
+		})
+	}
+}
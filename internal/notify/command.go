@@ -0,0 +1,55 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"kintai/internal/mode"
+)
+
+// commandNotifier はローカルのコマンドを実行する通知先。
+// 通知の内容は環境変数 KN_EVENT / KN_MODE / KN_MODE_DESCRIPTION / KN_TEXT / KN_TIME で渡す。
+// トークンなどを渡さないよう、kn の環境変数は PATH と HOME のほかは引き継がない。
+type commandNotifier struct {
+	target Target
+	opts   Options
+}
+
+func (c *commandNotifier) Name() string { return c.target.displayName() }
+
+func (c *commandNotifier) OnStart(ctx context.Context, m mode.Mode) error {
+	return c.run(ctx, c.target.startEvent(m, c.opts.now()))
+}
+
+func (c *commandNotifier) OnEnd(ctx context.Context) error {
+	return c.run(ctx, c.target.endEvent(c.opts.now()))
+}
+
+func (c *commandNotifier) run(ctx context.Context, ev event) error {
+	cmd := exec.CommandContext(ctx, c.target.Command[0], c.target.Command[1:]...)
+	cmd.Env = []string{
+		"PATH=" + os.Getenv("PATH"),
+		"HOME=" + os.Getenv("HOME"),
+		"KN_EVENT=" + ev.Kind,
+		"KN_MODE=" + ev.Mode.Name,
+		"KN_MODE_DESCRIPTION=" + ev.Mode.Description,
+		"KN_TEXT=" + ev.Text,
+		"KN_TIME=" + ev.Time.Format(time.RFC3339),
+	}
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		msg := strings.TrimSpace(string(out))
+		if len(msg) > 200 {
+			msg = msg[:200] + "..."
+		}
+		if msg != "" {
+			return fmt.Errorf("%s command failed: %w: %s", c.Name(), err, msg)
+		}
+		return fmt.Errorf("%s command failed: %w", c.Name(), err)
+	}
+	return nil
+}
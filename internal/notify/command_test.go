@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"kintai/internal/mode"
+)
+
+func TestCommandNotifier(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	// トークンなど kn の環境変数はコマンドに渡さない
+	t.Setenv("SLACK_TOKEN", "xoxb-secret")
+	n, err := New(Target{
+		Type:    TypeCommand,
+		Name:    "hook",
+		Command: []string{"sh", "-c", `echo "$KN_EVENT $KN_MODE $KN_TEXT $KN_TIME$SLACK_TOKEN" >> "$0"`, out},
+	}, testOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Name() != "hook" {
+		t.Errorf("Name() = %q, want hook", n.Name())
+	}
+	if err := n.OnStart(context.Background(), mode.Mode{Name: "office", Description: "出社"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.OnEnd(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "start office 業務を開始します（出社） 2026-10-19T09:00:00+09:00\nend  業務を終了します 2026-10-19T09:00:00+09:00\n"
+	if string(b) != want {
+		t.Errorf("output = %q, want %q", b, want)
+	}
+}
+
+func TestCommandNotifierFailure(t *testing.T) {
+	n, err := New(Target{Type: TypeCommand, Command: []string{"sh", "-c", "echo no route >&2; exit 3"}}, testOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = n.OnEnd(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "exit status 3") || !strings.Contains(err.Error(), "no route") {
+		t.Errorf("err = %v, want exit status 3 with stderr", err)
+	}
+}
@@ -0,0 +1,167 @@
+// Package notify は kn start / kn end の出社・退社を、Slack のリアクションや
+// Webhook・コマンドなどの通知先に知らせる。
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"kintai/internal/config"
+	"kintai/internal/mode"
+	"kintai/internal/retry"
+)
+
+// SkipError は処理が不要だったためスキップしたことを表す（リアクション済み・絵文字が未設定など）。失敗には数えない。
+// kn の結果の表でも、通知以外（ステータスの変更・休暇による打刻のスキップ）を含めてこの型でスキップを表す。
+type SkipError struct {
+	Reason string
+}
+
+func (e *SkipError) Error() string { return e.Reason }
+
+// 通知先の種類（Target.Type）。
+const (
+	TypeSlack   = "slack"   // 勤怠リマインダーへのリアクション
+	TypeWebhook = "webhook" // 任意の URL に JSON を POST
+	TypeTeams   = "teams"   // Microsoft Teams の Incoming Webhook
+	TypeDiscord = "discord" // Discord の Webhook
+	TypeCommand = "command" // ローカルのコマンドを実行
+)
+
+// Types は使える通知先の種類。
+var Types = []string{TypeSlack, TypeWebhook, TypeTeams, TypeDiscord, TypeCommand}
+
+// デフォルトの通知文言。{mode} は mode の説明（無ければ名前）に置き換える。
+const (
+	DefaultStartText = "業務を開始します（{mode}）"
+	DefaultEndText   = "業務を終了します"
+)
+
+// Notifier は出社・退社の通知先。
+type Notifier interface {
+	// Name は結果の表に出す名前（slack / teams など）。
+	Name() string
+	// OnStart は m で出社したことを知らせる。
+	OnStart(ctx context.Context, m mode.Mode) error
+	// OnEnd は退社したことを知らせる。
+	OnEnd(ctx context.Context) error
+}
+
+// Reverter は直前の OnStart / OnEnd を取り消せる Notifier（Slack のリアクションなど）。
+// kn start / kn end の --atomic で、打刻に失敗したときに使う。
+type Reverter interface {
+	Revert(ctx context.Context) error
+}
+
+// Target は通知先1つ分の設定。Type が slack なら他のフィールドは使わない（Slack の設定は slackkintai.Config）。
+type Target struct {
+	// Type は TypeWebhook / TypeTeams / TypeDiscord / TypeCommand のいずれか。
+	Type string
+	// Name は結果の表に出す名前。空なら Type。
+	Name string
+	// URL は webhook / teams / discord の送信先。
+	URL string
+	// Command は command で実行するコマンドと引数。シェルは介さない。
+	Command []string
+	// StartText / EndText は teams / discord などに送る文言。空ならデフォルトの文言。
+	StartText string
+	EndText   string
+}
+
+// Validate は設定の不足・形式の誤りをまとめて返す。Type が slack の Target は検証しない（slackkintai.Config で検証する）。
+func (t Target) Validate() error {
+	name := t.displayName()
+	var errs []error
+	switch t.Type {
+	case TypeSlack:
+	case TypeWebhook, TypeTeams, TypeDiscord:
+		if t.URL == "" {
+			errs = append(errs, fmt.Errorf("%w: notifier %s: url", config.ErrMissing, name))
+		} else if u, err := url.Parse(t.URL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("%w: notifier %s: url must be http(s)://...", config.ErrInvalid, name))
+		}
+	case TypeCommand:
+		if len(t.Command) == 0 || t.Command[0] == "" {
+			errs = append(errs, fmt.Errorf("%w: notifier %s: command", config.ErrMissing, name))
+		}
+	case "":
+		errs = append(errs, fmt.Errorf("%w: notifier type", config.ErrMissing))
+	default:
+		errs = append(errs, fmt.Errorf("%w: notifier type must be one of %s (got %q)", config.ErrInvalid, strings.Join(Types, " / "), t.Type))
+	}
+	return errors.Join(errs...)
+}
+
+func (t Target) displayName() string {
+	if t.Name != "" {
+		return t.Name
+	}
+	return t.Type
+}
+
+// Options は Notifier の動作の設定。ゼロ値のフィールドはデフォルト値で補われる。
+type Options struct {
+	// Retry は Webhook の再試行のポリシー（ゼロ値のフィールドは retry.Default）。
+	Retry retry.Policy
+	// Now は通知に載せる時刻を返す。nil なら現在時刻（日本時間）。
+	Now func() time.Time
+}
+
+func (o Options) now() time.Time {
+	if o.Now != nil {
+		return o.Now()
+	}
+	loc, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		return time.Now()
+	}
+	return time.Now().In(loc)
+}
+
+// New は t の Notifier を作る。Slack は NewSlack で作る。
+func New(t Target, opts Options) (Notifier, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	switch t.Type {
+	case TypeWebhook, TypeTeams, TypeDiscord:
+		return newWebhook(t, opts), nil
+	case TypeCommand:
+		return &commandNotifier{target: t, opts: opts}, nil
+	default:
+		return nil, fmt.Errorf("%w: notifier %s: use NewSlack", config.ErrInvalid, t.displayName())
+	}
+}
+
+// event は通知1回分の内容。
+type event struct {
+	// Kind は "start" / "end"。
+	Kind string
+	Mode mode.Mode
+	Text string
+	Time time.Time
+}
+
+func (t Target) startEvent(m mode.Mode, at time.Time) event {
+	text := t.StartText
+	if text == "" {
+		text = DefaultStartText
+	}
+	label := m.Description
+	if label == "" {
+		label = m.Name
+	}
+	return event{Kind: "start", Mode: m, Text: strings.ReplaceAll(text, "{mode}", label), Time: at}
+}
+
+func (t Target) endEvent(at time.Time) event {
+	text := t.EndText
+	if text == "" {
+		text = DefaultEndText
+	}
+	return event{Kind: "end", Text: text, Time: at}
+}
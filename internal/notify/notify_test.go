@@ -0,0 +1,64 @@
+package notify
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"kintai/internal/config"
+	"kintai/internal/mode"
+)
+
+func TestTargetValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		target Target
+		want   error
+	}{
+		{"slack", Target{Type: TypeSlack}, nil},
+		{"webhook", Target{Type: TypeWebhook, URL: "https://example.com/hook"}, nil},
+		{"command", Target{Type: TypeCommand, Command: []string{"true"}}, nil},
+		{"missing type", Target{}, config.ErrMissing},
+		{"unknown type", Target{Type: "line"}, config.ErrInvalid},
+		{"missing url", Target{Type: TypeTeams}, config.ErrMissing},
+		{"bad url", Target{Type: TypeDiscord, URL: "discord.com/api/webhooks/1"}, config.ErrInvalid},
+		{"missing command", Target{Type: TypeCommand}, config.ErrMissing},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.target.Validate()
+			if tt.want == nil && err != nil || tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("Validate() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRejectsSlack(t *testing.T) {
+	if _, err := New(Target{Type: TypeSlack}, Options{}); !errors.Is(err, config.ErrInvalid) {
+		t.Errorf("New(slack) err = %v, want config.ErrInvalid", err)
+	}
+}
+
+func TestSlackName(t *testing.T) {
+	if got := NewSlack(Target{Type: TypeSlack}, nil, false).Name(); got != "slack" {
+		t.Errorf("Name() = %q, want slack", got)
+	}
+	if got := NewSlack(Target{Type: TypeSlack, Name: "slack-work"}, nil, false).Name(); got != "slack-work" {
+		t.Errorf("Name() = %q, want slack-work", got)
+	}
+}
+
+func TestEventText(t *testing.T) {
+	remote := mode.Mode{Name: "remote", Description: "リモートワーク"}
+
+	if got := (Target{}).startEvent(remote, time.Time{}).Text; got != "業務を開始します（リモートワーク）" {
+		t.Errorf("default start text = %q", got)
+	}
+	if got := (Target{StartText: "{mode} で開始"}).startEvent(mode.Mode{Name: "office"}, time.Time{}).Text; got != "office で開始" {
+		t.Errorf("custom start text = %q", got)
+	}
+	if got := (Target{EndText: "おつかれさまでした"}).endEvent(time.Time{}).Text; got != "おつかれさまでした" {
+		t.Errorf("custom end text = %q", got)
+	}
+}
@@ -0,0 +1,69 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"kintai/internal/mode"
+	"kintai/internal/slackkintai"
+)
+
+// slackNotifier は勤怠リマインダーのスレにリアクションする通知先。
+// 付けたリアクションを覚えておき、Revert で外す。
+type slackNotifier struct {
+	target Target
+	client *slackkintai.Client
+	force  bool
+	last   slackkintai.Reaction
+}
+
+// NewSlack は sc で開始スレ・終了スレにリアクションする Notifier を返す。t は名前（Name）だけを使う。
+// force が false で既にリアクション済みなら、OnStart / OnEnd は *SkipError を返す。
+func NewSlack(t Target, sc *slackkintai.Client, force bool) Notifier {
+	return &slackNotifier{target: t, client: sc, force: force}
+}
+
+func (s *slackNotifier) Name() string { return s.target.displayName() }
+
+func (s *slackNotifier) OnStart(ctx context.Context, m mode.Mode) error {
+	r, err := s.client.ReactStart(ctx, m.Name, s.force)
+	return s.reacted("開始", r, err)
+}
+
+func (s *slackNotifier) OnEnd(ctx context.Context) error {
+	r, err := s.client.ReactEnd(ctx, s.force)
+	return s.reacted("終了", r, err)
+}
+
+// reacted は thread（開始・終了）のスレへのリアクションの結果を覚え、ReactionResult で返す。
+func (s *slackNotifier) reacted(thread string, r slackkintai.Reaction, err error) error {
+	s.last = r
+	return ReactionResult(thread, r, err)
+}
+
+// ReactionResult は thread（開始・終了・休憩入りなど）のスレへのリアクションの結果を返す。
+// リアクション済み（*slackkintai.ErrAlreadyReacted）と絵文字が未設定（ゼロ値の Reaction）は *SkipError にする。
+func ReactionResult(thread string, r slackkintai.Reaction, err error) error {
+	var already *slackkintai.ErrAlreadyReacted
+	if errors.As(err, &already) {
+		return &SkipError{Reason: fmt.Sprintf("リアクション済みのためスキップ (%s: :%s:)", thread, strings.Join(already.Emojis, ": :"))}
+	}
+	if err != nil {
+		return err
+	}
+	if r.IsZero() {
+		return &SkipError{Reason: fmt.Sprintf("%sの絵文字が未設定のためスキップ", thread)}
+	}
+	return nil
+}
+
+// Revert は直前に付けたリアクションを外す。
+func (s *slackNotifier) Revert(ctx context.Context) error {
+	if err := s.client.RemoveReaction(ctx, s.last); err != nil {
+		return err
+	}
+	s.last = slackkintai.Reaction{}
+	return nil
+}
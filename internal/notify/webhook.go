@@ -0,0 +1,136 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"kintai/internal/mode"
+	"kintai/internal/retry"
+)
+
+const webhookTimeout = 10 * time.Second
+
+// webhookNotifier は Webhook に JSON を POST する通知先（webhook / teams / discord）。
+type webhookNotifier struct {
+	target Target
+	opts   Options
+	http   *http.Client
+}
+
+func newWebhook(t Target, opts Options) *webhookNotifier {
+	return &webhookNotifier{target: t, opts: opts, http: &http.Client{Timeout: webhookTimeout}}
+}
+
+func (w *webhookNotifier) Name() string { return w.target.displayName() }
+
+func (w *webhookNotifier) OnStart(ctx context.Context, m mode.Mode) error {
+	return w.post(ctx, w.target.startEvent(m, w.opts.now()))
+}
+
+func (w *webhookNotifier) OnEnd(ctx context.Context) error {
+	return w.post(ctx, w.target.endEvent(w.opts.now()))
+}
+
+// webhookPayload は type = "webhook" で送る JSON。
+type webhookPayload struct {
+	Event           string `json:"event"`
+	Mode            string `json:"mode,omitempty"`
+	ModeDescription string `json:"mode_description,omitempty"`
+	Text            string `json:"text"`
+	Time            string `json:"time"`
+}
+
+// payload は通知先の種類ごとの本文を返す。
+func (w *webhookNotifier) payload(ev event) any {
+	switch w.target.Type {
+	case TypeTeams:
+		return map[string]string{"text": ev.Text}
+	case TypeDiscord:
+		return map[string]string{"content": ev.Text}
+	default:
+		return webhookPayload{
+			Event:           ev.Kind,
+			Mode:            ev.Mode.Name,
+			ModeDescription: ev.Mode.Description,
+			Text:            ev.Text,
+			Time:            ev.Time.Format(time.RFC3339),
+		}
+	}
+}
+
+// post は ev を POST する。投稿は二重に届くと困るので、
+// 届いていないと確かな失敗（接続できない）とレート制限（429）だけ再試行する。
+func (w *webhookNotifier) post(ctx context.Context, ev event) error {
+	body, err := json.Marshal(w.payload(ev))
+	if err != nil {
+		return err
+	}
+	err = retry.Do(ctx, w.opts.Retry, retryableWebhook, func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.target.URL, bytes.NewReader(body))
+		if err != nil {
+			return redactURL(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := w.http.Do(req)
+		if err != nil {
+			return redactURL(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			b, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+			return &statusError{code: resp.StatusCode, status: resp.Status, body: string(b), retryAfter: retryAfter(resp.Header)}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s webhook failed: %w", w.Name(), err)
+	}
+	return nil
+}
+
+// statusError は Webhook が 2xx 以外を返したことを表す。
+type statusError struct {
+	code       int
+	status     string
+	body       string
+	retryAfter time.Duration
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("POST failed: %s body=%s", e.status, e.body)
+}
+
+// redactURL は err が *url.Error なら、URL を除いたエラーを返す。
+// Webhook の URL はそれだけで投稿できるシークレットなので、エラーメッセージに出さない。
+func redactURL(err error) error {
+	var ue *url.Error
+	if errors.As(err, &ue) {
+		return fmt.Errorf("%s: %w", ue.Op, ue.Err)
+	}
+	return err
+}
+
+func retryableWebhook(err error) (bool, time.Duration) {
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.code == http.StatusTooManyRequests, se.retryAfter
+	}
+	return retry.NotSent(err), 0
+}
+
+// retryAfter は Retry-After ヘッダー（秒数）を返す。無ければ 0。
+func retryAfter(h http.Header) time.Duration {
+	sec, err := strconv.ParseFloat(h.Get("Retry-After"), 64)
+	if err != nil || sec <= 0 {
+		return 0
+	}
+	return time.Duration(sec * float64(time.Second))
+}
@@ -0,0 +1,152 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"kintai/internal/mode"
+	"kintai/internal/retry"
+)
+
+// fakeWebhook は受けた POST の本文を記録し、statuses の順に応答する。statuses を使い切ったら 204 を返す。
+type fakeWebhook struct {
+	mu       sync.Mutex
+	bodies   []map[string]any
+	statuses []int
+}
+
+func newFakeWebhook(t *testing.T, statuses ...int) (*fakeWebhook, *httptest.Server) {
+	t.Helper()
+	fw := &fakeWebhook{statuses: statuses}
+	srv := httptest.NewServer(fw)
+	t.Cleanup(srv.Close)
+	return fw, srv
+}
+
+// testOptions は再試行の待ち時間を短くし、通知の時刻を固定した Options。
+var testOptions = Options{
+	Retry: retry.Policy{BaseDelay: time.Millisecond, MaxDelay: time.Millisecond},
+	Now:   func() time.Time { return time.Date(2026, 10, 19, 9, 0, 0, 0, time.FixedZone("JST", 9*60*60)) },
+}
+
+func (fw *fakeWebhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	var body map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || r.Header.Get("Content-Type") != "application/json" {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	fw.bodies = append(fw.bodies, body)
+	if len(fw.statuses) > 0 {
+		code := fw.statuses[0]
+		fw.statuses = fw.statuses[1:]
+		if code == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "0.001")
+		}
+		http.Error(w, http.StatusText(code), code)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func TestWebhookPayloads(t *testing.T) {
+	remote := mode.Mode{Name: "remote", Description: "リモートワーク"}
+	tests := []struct {
+		typ  string
+		want map[string]any
+	}{
+		{TypeWebhook, map[string]any{
+			"event": "start", "mode": "remote", "mode_description": "リモートワーク",
+			"text": "業務を開始します（リモートワーク）", "time": "2026-10-19T09:00:00+09:00",
+		}},
+		{TypeTeams, map[string]any{"text": "業務を開始します（リモートワーク）"}},
+		{TypeDiscord, map[string]any{"content": "業務を開始します（リモートワーク）"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.typ, func(t *testing.T) {
+			fw, srv := newFakeWebhook(t)
+			n, err := New(Target{Type: tt.typ, URL: srv.URL}, testOptions)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n.Name() != tt.typ {
+				t.Errorf("Name() = %q, want %q", n.Name(), tt.typ)
+			}
+			if err := n.OnStart(context.Background(), remote); err != nil {
+				t.Fatal(err)
+			}
+			if len(fw.bodies) != 1 {
+				t.Fatalf("posts = %d, want 1", len(fw.bodies))
+			}
+			for k, v := range tt.want {
+				if fw.bodies[0][k] != v {
+					t.Errorf("%s = %v, want %v", k, fw.bodies[0][k], v)
+				}
+			}
+			if len(fw.bodies[0]) != len(tt.want) {
+				t.Errorf("body = %v, want %v", fw.bodies[0], tt.want)
+			}
+		})
+	}
+}
+
+func TestWebhookEnd(t *testing.T) {
+	fw, srv := newFakeWebhook(t)
+	n, err := New(Target{Type: TypeWebhook, URL: srv.URL, EndText: "おつかれさまでした"}, testOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.OnEnd(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if b := fw.bodies[0]; b["event"] != "end" || b["text"] != "おつかれさまでした" || b["mode"] != nil {
+		t.Errorf("body = %v", b)
+	}
+}
+
+func TestWebhookRetriesRateLimit(t *testing.T) {
+	fw, srv := newFakeWebhook(t, http.StatusTooManyRequests)
+	n, _ := New(Target{Type: TypeDiscord, URL: srv.URL}, testOptions)
+	if err := n.OnEnd(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(fw.bodies) != 2 {
+		t.Errorf("posts = %d, want 2 (retried after 429)", len(fw.bodies))
+	}
+}
+
+func TestWebhookServerErrorNotRetried(t *testing.T) {
+	// 5xx は届いている可能性があり、再送すると二重に投稿されるので再試行しない
+	fw, srv := newFakeWebhook(t, http.StatusInternalServerError, http.StatusInternalServerError)
+	n, _ := New(Target{Type: TypeTeams, Name: "teams-dev", URL: srv.URL}, testOptions)
+	err := n.OnEnd(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "teams-dev") || !strings.Contains(err.Error(), "500") {
+		t.Errorf("err = %v, want 500 from teams-dev", err)
+	}
+	if len(fw.bodies) != 1 {
+		t.Errorf("posts = %d, want 1", len(fw.bodies))
+	}
+}
+
+func TestWebhookErrorHidesURL(t *testing.T) {
+	// Webhook の URL はシークレットなので、接続できなかったときのエラーに含めない
+	srv := httptest.NewServer(http.NotFoundHandler())
+	u := srv.URL + "/hooks/T000/B000/secret-token"
+	srv.Close()
+
+	n, _ := New(Target{Type: TypeWebhook, URL: u}, testOptions)
+	err := n.OnEnd(context.Background())
+	if err == nil {
+		t.Fatal("OnEnd succeeded against a closed server")
+	}
+	if strings.Contains(err.Error(), "secret-token") {
+		t.Errorf("err = %v, want the webhook URL redacted", err)
+	}
+}
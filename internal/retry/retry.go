@@ -0,0 +1,152 @@
+// Package retry は一時的なエラー（ネットワークの瞬断・5xx・レート制限）を
+// 指数バックオフ＋ジッターで再試行する共通のポリシーを提供する。
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Policy は再試行の回数と待ち時間。ゼロ値のフィールドは Default の値で補われる。
+type Policy struct {
+	// MaxAttempts は最初の1回を含む最大試行回数。1 なら再試行しない。
+	MaxAttempts int
+	// BaseDelay は1回目の再試行までの待ち時間の目安。以降は倍々に増える。
+	BaseDelay time.Duration
+	// MaxDelay は1回あたりの待ち時間の上限（Retry-After の指定を除く）。
+	MaxDelay time.Duration
+	// Sleep は再試行までの待ち方。nil なら d だけ待つ（ctx がキャンセルされたら中断する）。
+	// テストで待ち時間を記録する・待たないようにするときに指定する。
+	Sleep func(ctx context.Context, d time.Duration) error
+}
+
+// Default はデフォルトのポリシー。
+var Default = Policy{MaxAttempts: 3, BaseDelay: 500 * time.Millisecond, MaxDelay: 8 * time.Second}
+
+// FromEnv は Default に環境変数 KN_RETRY_MAX_ATTEMPTS（最大試行回数）・KN_RETRY_BASE_DELAY・
+// KN_RETRY_MAX_DELAY（待ち時間。"500ms" などの time.ParseDuration の形式）を反映したポリシーを返す。
+// 値が不正なら無視する。
+func FromEnv() Policy {
+	p := Default
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("KN_RETRY_MAX_ATTEMPTS"))); err == nil && n > 0 {
+		p.MaxAttempts = n
+	}
+	if d, err := time.ParseDuration(strings.TrimSpace(os.Getenv("KN_RETRY_BASE_DELAY"))); err == nil && d > 0 {
+		p.BaseDelay = d
+	}
+	if d, err := time.ParseDuration(strings.TrimSpace(os.Getenv("KN_RETRY_MAX_DELAY"))); err == nil && d > 0 {
+		p.MaxDelay = d
+	}
+	return p
+}
+
+func (p Policy) withDefaults() Policy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = Default.MaxAttempts
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = Default.BaseDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = Default.MaxDelay
+	}
+	if p.Sleep == nil {
+		p.Sleep = sleep
+	}
+	return p
+}
+
+// Classifier はエラーを再試行してよいかを判定する。
+// after が正なら、バックオフの代わりにその時間だけ待つ（Retry-After）。
+type Classifier func(err error) (retryable bool, after time.Duration)
+
+// Do は fn を呼び、classify が再試行可能と判定したエラーの間は p に従って待ってから呼び直す。
+// 再試行しても失敗したときは最後のエラーを返す。ctx がキャンセルされたら待つのをやめて ctx のエラーを返す。
+func Do(ctx context.Context, p Policy, classify Classifier, fn func() error) error {
+	p = p.withDefaults()
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		retryable, after := classify(err)
+		if !retryable {
+			return err
+		}
+		if attempt >= p.MaxAttempts {
+			return fmt.Errorf("%w (gave up after %d attempts)", err, attempt)
+		}
+		wait := after
+		if wait <= 0 {
+			wait = p.backoff(attempt)
+		}
+		if err := p.Sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// backoff は attempt 回目の失敗のあとに待つ時間を返す。
+// BaseDelay*2^(attempt-1) を MaxDelay で頭打ちにし、その半分〜全部の間でランダムに散らす。
+func (p Policy) backoff(attempt int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < attempt && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	d = min(d, p.MaxDelay)
+	return d/2 + rand.N(d/2+1)
+}
+
+// sleep は d だけ待つ。ctx がキャンセルされたら ctx のエラーを返す。
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// Transient は err がネットワークの一時的な失敗（タイムアウト・接続の切断・名前解決の失敗など）かを返す。
+// キャンセル・期限切れの context は一時的とみなさない。
+func Transient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() {
+		return true
+	}
+	var op *net.OpError
+	var dns *net.DNSError
+	return errors.As(err, &op) || errors.As(err, &dns)
+}
+
+// NotSent は err がリクエストを送る前の失敗（接続・名前解決の失敗）で、相手に届いていないことが確かかを返す。
+// 冪等でないリクエスト（打刻のPOSTなど）は、これが true のときだけ再試行してよい。
+func NotSent(err error) bool {
+	if err == nil {
+		return false
+	}
+	var dns *net.DNSError
+	if errors.As(err, &dns) {
+		return true
+	}
+	var op *net.OpError
+	return errors.As(err, &op) && op.Op == "dial"
+}
@@ -0,0 +1,137 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// noSleep は待ち時間を記録するだけで待たない Sleep を p に設定する。
+func noSleep(p Policy) (Policy, *[]time.Duration) {
+	var waits []time.Duration
+	p.Sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return ctx.Err()
+	}
+	return p, &waits
+}
+
+var errTemporary = errors.New("temporary")
+
+func classifyTemporary(err error) (bool, time.Duration) { return errors.Is(err, errTemporary), 0 }
+
+func TestDoRetriesUntilSuccess(t *testing.T) {
+	p, waits := noSleep(Policy{MaxAttempts: 3, BaseDelay: time.Second})
+	calls := 0
+	err := Do(context.Background(), p, classifyTemporary, func() error {
+		calls++
+		if calls < 3 {
+			return errTemporary
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("err = %v, calls = %d", err, calls)
+	}
+	// ジッターで 1/2〜1 倍に散らすので、1回目は 0.5〜1s、2回目は 1〜2s
+	if len(*waits) != 2 || (*waits)[0] < 500*time.Millisecond || (*waits)[0] > time.Second ||
+		(*waits)[1] < time.Second || (*waits)[1] > 2*time.Second {
+		t.Errorf("waits = %v", *waits)
+	}
+}
+
+func TestDoGivesUp(t *testing.T) {
+	p, _ := noSleep(Policy{MaxAttempts: 2})
+	calls := 0
+	err := Do(context.Background(), p, classifyTemporary, func() error {
+		calls++
+		return errTemporary
+	})
+	if !errors.Is(err, errTemporary) || calls != 2 {
+		t.Fatalf("err = %v, calls = %d", err, calls)
+	}
+}
+
+func TestDoPermanentError(t *testing.T) {
+	p, waits := noSleep(Policy{})
+	permanent := errors.New("permanent")
+	calls := 0
+	err := Do(context.Background(), p, classifyTemporary, func() error {
+		calls++
+		return permanent
+	})
+	if err != permanent || calls != 1 || len(*waits) != 0 {
+		t.Fatalf("err = %v, calls = %d, waits = %v", err, calls, *waits)
+	}
+}
+
+func TestDoRetryAfter(t *testing.T) {
+	p, waits := noSleep(Policy{MaxAttempts: 2, MaxDelay: time.Second})
+	calls := 0
+	classify := func(error) (bool, time.Duration) { return true, 30 * time.Second }
+	_ = Do(context.Background(), p, classify, func() error {
+		calls++
+		return errTemporary
+	})
+	// Retry-After は MaxDelay で頭打ちにしない
+	if len(*waits) != 1 || (*waits)[0] != 30*time.Second {
+		t.Errorf("waits = %v", *waits)
+	}
+}
+
+func TestDoCanceled(t *testing.T) {
+	p, _ := noSleep(Policy{})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := Do(ctx, p, classifyTemporary, func() error { return errTemporary })
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestBackoffCapped(t *testing.T) {
+	p := Policy{BaseDelay: time.Second, MaxDelay: 4 * time.Second}.withDefaults()
+	for i := 0; i < 20; i++ {
+		if d := p.backoff(10); d < 2*time.Second || d > 4*time.Second {
+			t.Fatalf("backoff(10) = %v", d)
+		}
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("KN_RETRY_MAX_ATTEMPTS", "5")
+	t.Setenv("KN_RETRY_BASE_DELAY", "10ms")
+	t.Setenv("KN_RETRY_MAX_DELAY", "bogus")
+	p := FromEnv()
+	if p.MaxAttempts != 5 || p.BaseDelay != 10*time.Millisecond || p.MaxDelay != Default.MaxDelay {
+		t.Errorf("FromEnv = %+v", p)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	dial := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	read := &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}
+	tests := []struct {
+		name               string
+		err                error
+		transient, notSent bool
+	}{
+		{"dial", dial, true, true},
+		{"dns", &net.DNSError{Err: "no such host", Name: "example.invalid"}, true, true},
+		{"read", read, true, false},
+		{"canceled", context.Canceled, false, false},
+		{"other", errors.New("boom"), false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Transient(tt.err); got != tt.transient {
+				t.Errorf("Transient = %v, want %v", got, tt.transient)
+			}
+			if got := NotSent(tt.err); got != tt.notSent {
+				t.Errorf("NotSent = %v, want %v", got, tt.notSent)
+			}
+		})
+	}
+}
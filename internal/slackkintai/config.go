@@ -0,0 +1,165 @@
+package slackkintai
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"kintai/internal/auth"
+	"kintai/internal/config"
+	"kintai/internal/retry"
+)
+
+var (
+	reChannelID   = regexp.MustCompile(`^[CG][A-Z0-9]{8,}$`)
+	reChannelName = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,79}$`)
+	// コロンなしの絵文字名（shussha, remote-start, +1 など）
+	reEmojiName = regexp.MustCompile(`^[a-z0-9_+'-]+$`)
+)
+
+// デフォルトのリマインダー文言と絵文字。
+const (
+	DefaultStartText = "リマインダー : 業務開始スレ"
+	DefaultEndText   = "リマインダー : 業務終了スレ"
+	DefaultEndEmoji  = "tai-kin"
+)
+
+// DefaultStartEmojis はデフォルトの mode → 開始用絵文字の対応。
+var DefaultStartEmojis = map[string]string{
+	"office": "shussha",
+	"remote": "remote-start",
+}
+
+// Config は Slack 連携の設定。
+type Config struct {
+	// Token は User Token（xoxp-...）。
+	Token string
+	// Channel はリマインダーが投稿されるチャンネルのID（Cxxxx）またはチャンネル名。
+	Channel string
+
+	// StartReminder / EndReminder は開始スレ・終了スレのリマインダーを探す条件。
+	// Text が空ならデフォルトの文言と完全一致で探す。
+	StartReminder Reminder
+	EndReminder   Reminder
+	// StartEmojis は mode → 開始スレに付ける絵文字名。nil なら DefaultStartEmojis。
+	StartEmojis map[string]string
+	// EndEmoji は終了スレに付ける絵文字名。空なら DefaultEndEmoji。
+	EndEmoji string
+
+	// BreakStartEmoji / BreakEndEmoji は休憩入り・休憩戻りで開始スレに付ける絵文字名。
+	// 空ならリアクションしない。
+	BreakStartEmoji string
+	BreakEndEmoji   string
+	// BreakStatus は休憩中に設定する Slack のステータス。Text と Emoji が空なら設定しない。
+	BreakStatus UserStatus
+	// OutStatus は外出中に設定する Slack のステータス。Text と Emoji が空なら設定しない。
+	OutStatus UserStatus
+
+	// Retry は一時的な失敗（ネットワークの瞬断・5xx・レート制限）の再試行のポリシー。
+	// ゼロ値なら retry.Default。
+	Retry retry.Policy
+}
+
+// withDefaults は空の項目をデフォルト値で埋めた Config を返す。
+func (c Config) withDefaults() Config {
+	if c.StartReminder.Text == "" {
+		c.StartReminder = Reminder{Match: MatchExact, Text: DefaultStartText}
+	}
+	if c.EndReminder.Text == "" {
+		c.EndReminder = Reminder{Match: MatchExact, Text: DefaultEndText}
+	}
+	if len(c.StartEmojis) == 0 {
+		c.StartEmojis = DefaultStartEmojis
+	}
+	if c.EndEmoji == "" {
+		c.EndEmoji = DefaultEndEmoji
+	}
+	return c
+}
+
+// Modes は開始スレで使える mode 名を昇順で返す。
+func (c Config) Modes() []string {
+	return slices.Sorted(maps.Keys(c.withDefaults().StartEmojis))
+}
+
+// ConfigFromEnv は SLACK_TOKEN / SLACK_CHANNEL から Config を組み立てる。検証はしない。
+// SLACK_TOKEN が環境変数に無ければシークレットストア（auth.SecretStore）から読む。
+func ConfigFromEnv() (Config, error) {
+	token, err := auth.LookupSecret("SLACK_TOKEN")
+	if err != nil {
+		return Config{}, err
+	}
+	return Config{
+		Token:   token,
+		Channel: strings.TrimPrefix(strings.TrimSpace(os.Getenv("SLACK_CHANNEL")), "#"),
+		Retry:   retry.FromEnv(),
+	}, nil
+}
+
+// Validate は設定の不足・形式の誤りをすべて集めて返す。問題がなければ nil。
+// 返すエラーは errors.Is で config.ErrMissing / config.ErrInvalid と照合できる。
+func (c Config) Validate() error {
+	c = c.withDefaults()
+	var errs []error
+
+	switch {
+	case c.Token == "":
+		errs = append(errs, fmt.Errorf("%w: SLACK_TOKEN", config.ErrMissing))
+	case !strings.HasPrefix(c.Token, "xoxp-"):
+		errs = append(errs, fmt.Errorf("%w: SLACK_TOKEN must be a user token (xoxp-...)", config.ErrInvalid))
+	}
+
+	switch {
+	case c.Channel == "":
+		errs = append(errs, fmt.Errorf("%w: SLACK_CHANNEL", config.ErrMissing))
+	case looksLikeChannelID(c.Channel):
+		if !reChannelID.MatchString(c.Channel) {
+			errs = append(errs, fmt.Errorf("%w: SLACK_CHANNEL %q is not a valid channel ID (Cxxxxxxxx)", config.ErrInvalid, c.Channel))
+		}
+	case !reChannelName.MatchString(c.Channel):
+		errs = append(errs, fmt.Errorf("%w: SLACK_CHANNEL %q is neither a channel ID nor a channel name", config.ErrInvalid, c.Channel))
+	}
+
+	if _, err := c.StartReminder.matcher(); err != nil {
+		errs = append(errs, fmt.Errorf("%w: start reminder: %v", config.ErrInvalid, err))
+	}
+	if _, err := c.EndReminder.matcher(); err != nil {
+		errs = append(errs, fmt.Errorf("%w: end reminder: %v", config.ErrInvalid, err))
+	}
+	for mode, emoji := range c.StartEmojis {
+		if !reEmojiName.MatchString(emoji) {
+			errs = append(errs, fmt.Errorf("%w: emoji for mode %q: %q is not an emoji name", config.ErrInvalid, mode, emoji))
+		}
+	}
+	if !reEmojiName.MatchString(c.EndEmoji) {
+		errs = append(errs, fmt.Errorf("%w: end emoji: %q is not an emoji name", config.ErrInvalid, c.EndEmoji))
+	}
+	for _, e := range []struct{ name, emoji string }{
+		{"break start emoji", c.BreakStartEmoji},
+		{"break end emoji", c.BreakEndEmoji},
+		{"break status emoji", c.BreakStatus.Emoji},
+		{"out status emoji", c.OutStatus.Emoji},
+	} {
+		if e.emoji != "" && !reEmojiName.MatchString(e.emoji) {
+			errs = append(errs, fmt.Errorf("%w: %s: %q is not an emoji name", config.ErrInvalid, e.name, e.emoji))
+		}
+	}
+	if c.BreakStatus.Expiration < 0 {
+		errs = append(errs, fmt.Errorf("%w: break status expiration must not be negative", config.ErrInvalid))
+	}
+	if c.OutStatus.Expiration < 0 {
+		errs = append(errs, fmt.Errorf("%w: out status expiration must not be negative", config.ErrInvalid))
+	}
+
+	return errors.Join(errs...)
+}
+
+// looksLikeChannelID はチャンネル名ではなくIDとして扱う入力かを返す。
+// チャンネル名は小文字のみなので、大文字の C / G で始まればIDとみなす。
+func looksLikeChannelID(s string) bool {
+	return strings.HasPrefix(s, "C") || strings.HasPrefix(s, "G")
+}
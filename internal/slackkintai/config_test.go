@@ -0,0 +1,44 @@
+package slackkintai
+
+import (
+	"errors"
+	"testing"
+
+	"kintai/internal/config"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		missing bool
+		invalid bool
+	}{
+		{name: "ok id", cfg: Config{Token: "xoxp-1-2-3", Channel: "C01ABCDEF23"}},
+		{name: "ok name", cfg: Config{Token: "xoxp-1-2-3", Channel: "kintai-reminder"}},
+		{name: "all missing", cfg: Config{}, missing: true},
+		{name: "bot token", cfg: Config{Token: "xoxb-1-2-3", Channel: "C01ABCDEF23"}, invalid: true},
+		{name: "short id", cfg: Config{Token: "xoxp-1", Channel: "C12"}, invalid: true},
+		{name: "bad name", cfg: Config{Token: "xoxp-1", Channel: "勤怠 チャンネル"}, invalid: true},
+		{name: "missing and invalid", cfg: Config{Token: "xoxb-1"}, missing: true, invalid: true},
+		{name: "break emojis", cfg: Config{Token: "xoxp-1", Channel: "kintai", BreakStartEmoji: "kyukei", BreakStatus: UserStatus{Text: "休憩中", Emoji: "coffee"}}},
+		{name: "bad break emoji", cfg: Config{Token: "xoxp-1", Channel: "kintai", BreakEndEmoji: ":back:"}, invalid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if got := errors.Is(err, config.ErrMissing); got != tt.missing {
+				t.Errorf("Is(config.ErrMissing) = %v, want %v (err=%v)", got, tt.missing, err)
+			}
+			if got := errors.Is(err, config.ErrInvalid); got != tt.invalid {
+				t.Errorf("Is(config.ErrInvalid) = %v, want %v (err=%v)", got, tt.invalid, err)
+			}
+		})
+	}
+}
+
+func TestNewRejectsInvalidConfig(t *testing.T) {
+	if _, err := New(Config{}); !errors.Is(err, config.ErrMissing) {
+		t.Fatalf("err = %v, want config.ErrMissing", err)
+	}
+}
@@ -0,0 +1,24 @@
+package slackkintai
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrReminderNotFound は当日のリマインダーメッセージが見つからなかったことを表す。
+	ErrReminderNotFound = errors.New("reminder message not found")
+	// ErrReminderAmbiguous は当日のリマインダーメッセージが複数見つかり特定できなかったことを表す。
+	ErrReminderAmbiguous = errors.New("reminder message not unique")
+)
+
+// ErrAlreadyReacted は自分のリアクションが既に付いているため何もしなかったことを表す。
+type ErrAlreadyReacted struct {
+	// Emojis は既に付いている自分の勤怠用の絵文字。
+	Emojis []string
+}
+
+func (e *ErrAlreadyReacted) Error() string {
+	return fmt.Sprintf("already reacted: :%s:", strings.Join(e.Emojis, ": :"))
+}
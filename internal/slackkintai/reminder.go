@@ -0,0 +1,54 @@
+package slackkintai
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// リマインダーメッセージの照合方法。
+const (
+	MatchExact  = "exact"  // 本文が Text と完全一致
+	MatchPrefix = "prefix" // 本文が Text で始まる
+	MatchRegex  = "regex"  // 本文が正規表現 Text にマッチ
+)
+
+// Reminder は当日のリマインダーメッセージを探す条件。
+type Reminder struct {
+	// Match は MatchExact / MatchPrefix / MatchRegex のいずれか。空なら MatchExact。
+	Match string
+	// Text は照合する文字列（MatchRegex なら正規表現）。
+	Text string
+}
+
+func (r Reminder) String() string {
+	return fmt.Sprintf("%s %q", r.kind(), r.Text)
+}
+
+func (r Reminder) kind() string {
+	if r.Match == "" {
+		return MatchExact
+	}
+	return r.Match
+}
+
+// matcher は本文がリマインダーに該当するかを判定する関数を返す。
+func (r Reminder) matcher() (func(string) bool, error) {
+	if r.Text == "" {
+		return nil, fmt.Errorf("reminder text is empty")
+	}
+	switch r.kind() {
+	case MatchExact:
+		return func(s string) bool { return s == r.Text }, nil
+	case MatchPrefix:
+		return func(s string) bool { return strings.HasPrefix(s, r.Text) }, nil
+	case MatchRegex:
+		re, err := regexp.Compile(r.Text)
+		if err != nil {
+			return nil, fmt.Errorf("reminder regex %q: %w", r.Text, err)
+		}
+		return re.MatchString, nil
+	default:
+		return nil, fmt.Errorf("reminder match must be exact, prefix or regex (got %q)", r.Match)
+	}
+}
@@ -0,0 +1,63 @@
+package slackkintai
+
+import (
+	"errors"
+	"testing"
+
+	"kintai/internal/config"
+)
+
+func TestReminderMatcher(t *testing.T) {
+	tests := []struct {
+		r    Reminder
+		text string
+		want bool
+	}{
+		{Reminder{Text: "リマインダー : 業務開始スレ"}, "リマインダー : 業務開始スレ", true},
+		{Reminder{Text: "リマインダー : 業務開始スレ"}, "リマインダー : 業務開始スレ です", false},
+		{Reminder{Match: MatchPrefix, Text: "Reminder: start"}, "Reminder: start thread (10/18)", true},
+		{Reminder{Match: MatchPrefix, Text: "Reminder: start"}, "reminder: start", false},
+		{Reminder{Match: MatchRegex, Text: `^業務(開始|スタート)`}, "業務スタートのスレッド", true},
+		{Reminder{Match: MatchRegex, Text: `^業務(開始|スタート)`}, "本日の業務開始", false},
+	}
+	for _, tt := range tests {
+		match, err := tt.r.matcher()
+		if err != nil {
+			t.Fatalf("%v: %v", tt.r, err)
+		}
+		if got := match(tt.text); got != tt.want {
+			t.Errorf("%v.match(%q) = %v, want %v", tt.r, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestConfigValidateReminderAndEmoji(t *testing.T) {
+	base := Config{Token: "xoxp-1", Channel: "C01ABCDEF23"}
+
+	cfg := base
+	cfg.StartReminder = Reminder{Match: MatchRegex, Text: "(unclosed"}
+	if err := cfg.Validate(); !errors.Is(err, config.ErrInvalid) {
+		t.Errorf("bad regex: err = %v, want config.ErrInvalid", err)
+	}
+
+	cfg = base
+	cfg.EndReminder = Reminder{Match: "fuzzy", Text: "x"}
+	if err := cfg.Validate(); !errors.Is(err, config.ErrInvalid) {
+		t.Errorf("bad match kind: err = %v, want config.ErrInvalid", err)
+	}
+
+	cfg = base
+	cfg.StartEmojis = map[string]string{"client-site": ":client:"}
+	if err := cfg.Validate(); !errors.Is(err, config.ErrInvalid) {
+		t.Errorf("emoji with colons: err = %v, want config.ErrInvalid", err)
+	}
+
+	cfg = base
+	cfg.StartEmojis = map[string]string{"office": "shussha", "client-site": "client_site"}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("custom emoji table: %v", err)
+	}
+	if got := cfg.Modes(); len(got) != 2 || got[0] != "client-site" || got[1] != "office" {
+		t.Errorf("Modes = %v", got)
+	}
+}
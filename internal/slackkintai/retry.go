@@ -0,0 +1,49 @@
+package slackkintai
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"kintai/internal/retry"
+)
+
+// call は Slack API の呼び出し fn を c.cfg.Retry に従って再試行する。
+// 何度呼んでも結果が変わらない API（取得・ステータスの設定など）に使う。
+func (c *Client) call(ctx context.Context, fn func() error) error {
+	return retry.Do(ctx, c.cfg.Retry, retryable, fn)
+}
+
+// retryable はレート制限（Retry-After に従って待つ）、5xx、ネットワークの一時的な失敗を再試行する。
+func retryable(err error) (bool, time.Duration) {
+	var rl *slack.RateLimitedError
+	if errors.As(err, &rl) {
+		return true, rl.RetryAfter
+	}
+	var sc slack.StatusCodeError
+	if errors.As(err, &sc) {
+		return sc.Code >= 500, 0
+	}
+	return retry.Transient(err), 0
+}
+
+// retryableNotSent は投稿など冪等でない API の失敗のうち、処理されていないと確かなものだけ再試行する。
+// レート制限はリクエストを受け付けずに返されるので再試行してよい。
+func retryableNotSent(err error) (bool, time.Duration) {
+	var rl *slack.RateLimitedError
+	if errors.As(err, &rl) {
+		return true, rl.RetryAfter
+	}
+	return retry.NotSent(err), 0
+}
+
+// slackError は err が Slack API のエラー応答なら、そのエラーコード（"already_reacted" など）を返す。
+func slackError(err error) string {
+	var se slack.SlackErrorResponse
+	if errors.As(err, &se) {
+		return se.Err
+	}
+	return ""
+}
@@ -0,0 +1,48 @@
+package slackkintai
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func TestRetryable(t *testing.T) {
+	rateLimited := fmt.Errorf("reactions.add failed: %w", &slack.RateLimitedError{RetryAfter: 3 * time.Second})
+	tests := []struct {
+		name           string
+		err            error
+		retry, notSent bool
+		after          time.Duration
+	}{
+		{"rate limited", rateLimited, true, true, 3 * time.Second},
+		{"5xx", slack.StatusCodeError{Code: 502, Status: "502 Bad Gateway"}, true, false, 0},
+		{"4xx", slack.StatusCodeError{Code: 404, Status: "404 Not Found"}, false, false, 0},
+		{"api error", slack.SlackErrorResponse{Err: "channel_not_found"}, false, false, 0},
+		{"dial", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, true, true, 0},
+		{"read", &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}, true, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if ok, after := retryable(tt.err); ok != tt.retry || after != tt.after {
+				t.Errorf("retryable = %v, %v; want %v, %v", ok, after, tt.retry, tt.after)
+			}
+			if ok, _ := retryableNotSent(tt.err); ok != tt.notSent {
+				t.Errorf("retryableNotSent = %v, want %v", ok, tt.notSent)
+			}
+		})
+	}
+}
+
+func TestSlackError(t *testing.T) {
+	err := fmt.Errorf("reactions.add failed: %w", slack.SlackErrorResponse{Err: "already_reacted"})
+	if got := slackError(err); got != "already_reacted" {
+		t.Errorf("slackError = %q", got)
+	}
+	if got := slackError(errors.New("boom")); got != "" {
+		t.Errorf("slackError = %q, want empty", got)
+	}
+}
@@ -2,60 +2,273 @@ package slackkintai
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"os"
+	"maps"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/slack-go/slack"
-)
 
-const (
-	startText = "リマインダー : 業務開始スレ"
-	endText   = "リマインダー : 業務終了スレ"
+	"kintai/internal/retry"
 )
 
-func ReactStart(ctx context.Context, mode string) error {
-	var emoji string
-	switch mode {
-	case "office":
-		emoji = "shussha"
-	case "remote":
-		emoji = "remote-start"
-	default:
-		return fmt.Errorf("unknown mode: %s", mode)
+// ThreadStatus はリマインダースレ1つ分の状態。
+type ThreadStatus struct {
+	// Found は当日のリマインダーメッセージが見つかったか。
+	Found bool `json:"found"`
+	// Reacted は自分が勤怠用の絵文字でリアクション済みか。
+	Reacted bool `json:"reacted"`
+	// Emojis は自分が付けている勤怠用の絵文字。
+	Emojis []string `json:"emojis,omitempty"`
+}
+
+// Status は当日の開始スレ・終了スレへのリアクション状況。
+type Status struct {
+	Start ThreadStatus `json:"start"`
+	End   ThreadStatus `json:"end"`
+}
+
+// Client は Config を使って Slack にリアクションする。
+type Client struct {
+	api *slack.Client
+	cfg Config
+
+	start thread
+	end   thread
+}
+
+// thread は開始スレ・終了スレそれぞれの探し方と、勤怠用とみなす絵文字。
+type thread struct {
+	reminder Reminder
+	match    func(string) bool
+	// emojis は同じスレで「既に反応済み」とみなす絵文字の集合（出社とリモートなど）。
+	emojis []string
+}
+
+// New は cfg を検証して Client を返す。cfg に問題があれば Validate のエラーを返す。
+func New(cfg Config) (*Client, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
 	}
-	return reactExact(ctx, startText, emoji)
+	cfg = cfg.withDefaults()
+
+	startMatch, _ := cfg.StartReminder.matcher() // Validate 済み
+	endMatch, _ := cfg.EndReminder.matcher()
+
+	startEmojis := slices.Sorted(maps.Values(cfg.StartEmojis))
+	return &Client{
+		api:   slack.New(cfg.Token),
+		cfg:   cfg,
+		start: thread{reminder: cfg.StartReminder, match: startMatch, emojis: slices.Compact(startEmojis)},
+		end:   thread{reminder: cfg.EndReminder, match: endMatch, emojis: []string{cfg.EndEmoji}},
+	}, nil
+}
+
+// Reaction は付けたリアクション。RemoveReaction で取り消すときに使う。
+type Reaction struct {
+	Channel   string
+	Timestamp string
+	Emoji     string
+}
+
+// IsZero はリアクションを付けなかった（絵文字が未設定など）かを返す。
+func (r Reaction) IsZero() bool { return r.Emoji == "" }
+
+// ReactStart は開始スレに mode に応じた絵文字でリアクションし、付けたリアクションを返す。
+// force が false で自分の開始用リアクションが既にあれば *ErrAlreadyReacted を返す。
+func (c *Client) ReactStart(ctx context.Context, mode string, force bool) (Reaction, error) {
+	emoji, ok := c.cfg.StartEmojis[mode]
+	if !ok {
+		return Reaction{}, fmt.Errorf("unknown mode: %s (available: %s)", mode, strings.Join(c.cfg.Modes(), ", "))
+	}
+	return c.react(ctx, c.start, emoji, force)
+}
+
+// ReactEnd は終了スレにリアクションし、付けたリアクションを返す。
+// force が false で自分の終了用リアクションが既にあれば *ErrAlreadyReacted を返す。
+func (c *Client) ReactEnd(ctx context.Context, force bool) (Reaction, error) {
+	return c.react(ctx, c.end, c.cfg.EndEmoji, force)
 }
 
-func ReactEnd(ctx context.Context) error {
-	return reactExact(ctx, endText, "tai-kin")
+// ReactBreakStart は開始スレに休憩入りの絵文字でリアクションする。
+// BreakStartEmoji が未設定なら何もせずゼロ値を返す。
+func (c *Client) ReactBreakStart(ctx context.Context, force bool) (Reaction, error) {
+	return c.reactBreak(ctx, c.cfg.BreakStartEmoji, force)
 }
 
-func reactExact(ctx context.Context, exactText string, emoji string) error {
-	token := mustEnv("SLACK_TOKEN")
-	ch := mustEnv("SLACK_CHANNEL")
+// ReactBreakEnd は開始スレに休憩戻りの絵文字でリアクションする。
+// BreakEndEmoji が未設定なら何もせずゼロ値を返す。
+func (c *Client) ReactBreakEnd(ctx context.Context, force bool) (Reaction, error) {
+	return c.reactBreak(ctx, c.cfg.BreakEndEmoji, force)
+}
+
+// reactBreak は開始スレと同じメッセージに emoji を付ける。
+// 出社・リモートの絵文字とは別扱いにするため、反応済みの判定は emoji だけで行う。
+func (c *Client) reactBreak(ctx context.Context, emoji string, force bool) (Reaction, error) {
+	if emoji == "" {
+		return Reaction{}, nil
+	}
+	th := c.start
+	th.emojis = []string{emoji}
+	return c.react(ctx, th, emoji, force)
+}
+
+// RemoveReaction は r のリアクションを外す。打刻に失敗したときにリアクションを取り消すのに使う。
+func (c *Client) RemoveReaction(ctx context.Context, r Reaction) error {
+	if r.IsZero() {
+		return nil
+	}
+	item := slack.ItemRef{Channel: r.Channel, Timestamp: r.Timestamp}
+	attempts := 0
+	err := c.call(ctx, func() error {
+		attempts++
+		err := c.api.RemoveReactionContext(ctx, r.Emoji, item)
+		// 前の試行が届いていた場合は no_reaction になるので成功とみなす
+		if attempts > 1 && slackError(err) == "no_reaction" {
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("reactions.remove failed: %w", err)
+	}
+	return nil
+}
+
+// react は th のリマインダーメッセージに emoji を付ける。
+func (c *Client) react(ctx context.Context, th thread, emoji string, force bool) (Reaction, error) {
+	channelID, err := c.resolveChannelID(ctx)
+	if err != nil {
+		return Reaction{}, err
+	}
+
+	msg, err := c.findReminder(ctx, channelID, th)
+	if err != nil {
+		return Reaction{}, err
+	}
+	if msg == nil {
+		return Reaction{}, fmt.Errorf("%w: %s", ErrReminderNotFound, th.reminder)
+	}
 
-	api := slack.New(token)
-	channelID, err := resolveChannelID(api, ch)
+	me, err := c.authTest(ctx)
 	if err != nil {
+		return Reaction{}, err
+	}
+	mine := reactedEmojis(*msg, th.emojis, me.UserID)
+	// 同じ絵文字は force でも付け直せないのでスキップする
+	if slices.Contains(mine, emoji) || (!force && len(mine) > 0) {
+		return Reaction{}, &ErrAlreadyReacted{Emojis: mine}
+	}
+
+	item := slack.ItemRef{Channel: channelID, Timestamp: msg.Timestamp}
+	attempts := 0
+	err = c.call(ctx, func() error {
+		attempts++
+		err := c.api.AddReactionContext(ctx, emoji, item)
+		// 前の試行が届いていた場合は already_reacted になるので成功とみなす
+		if attempts > 1 && slackError(err) == "already_reacted" {
+			return nil
+		}
 		return err
+	})
+	if err != nil {
+		return Reaction{}, fmt.Errorf("reactions.add failed: %w", err)
 	}
+	return Reaction{Channel: channelID, Timestamp: msg.Timestamp, Emoji: emoji}, nil
+}
 
-	ts, err := findTSByExactText(api, channelID, exactText)
+// AuthTest はトークンが有効かを auth.test で確かめ、ユーザー名とワークスペース名を返す。
+func (c *Client) AuthTest(ctx context.Context) (user, team string, err error) {
+	res, err := c.authTest(ctx)
 	if err != nil {
+		return "", "", err
+	}
+	return res.User, res.Team, nil
+}
+
+// authTest は auth.test を呼ぶ。一時的な失敗は再試行する。
+func (c *Client) authTest(ctx context.Context) (*slack.AuthTestResponse, error) {
+	var res *slack.AuthTestResponse
+	err := c.call(ctx, func() (err error) {
+		res, err = c.api.AuthTestContext(ctx)
 		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("auth.test failed: %w", err)
 	}
+	return res, nil
+}
 
-	item := slack.ItemRef{Channel: channelID, Timestamp: ts}
-	if err := api.AddReactionContext(ctx, emoji, item); err != nil {
-		return fmt.Errorf("reactions.add failed: %w", err)
+// PostDM は自分宛ての DM（自分とのメッセージ）に text を投稿する。chat:write 権限が必要。
+func (c *Client) PostDM(ctx context.Context, text string) error {
+	me, err := c.authTest(ctx)
+	if err != nil {
+		return err
+	}
+	// 投稿は二重に届くと困るので、届いていないと確かな失敗だけ再試行する
+	err = retry.Do(ctx, c.cfg.Retry, retryableNotSent, func() error {
+		_, _, err := c.api.PostMessageContext(ctx, me.UserID, slack.MsgOptionText(text, false))
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("chat.postMessage failed: %w", err)
 	}
 	return nil
 }
 
-func findTSByExactText(api *slack.Client, channelID string, exact string) (string, error) {
+// Status は当日の開始スレ・終了スレに自分がリアクション済みかを調べる。
+// リマインダーがまだ投稿されていない場合はエラーにせず Found=false を返す。
+func (c *Client) Status(ctx context.Context) (Status, error) {
+	me, err := c.authTest(ctx)
+	if err != nil {
+		return Status{}, err
+	}
+	channelID, err := c.resolveChannelID(ctx)
+	if err != nil {
+		return Status{}, err
+	}
+
+	var st Status
+	if st.Start, err = c.threadStatus(ctx, channelID, c.start, me.UserID); err != nil {
+		return Status{}, err
+	}
+	if st.End, err = c.threadStatus(ctx, channelID, c.end, me.UserID); err != nil {
+		return Status{}, err
+	}
+	return st, nil
+}
+
+func (c *Client) threadStatus(ctx context.Context, channelID string, th thread, userID string) (ThreadStatus, error) {
+	msg, err := c.findReminder(ctx, channelID, th)
+	if err != nil {
+		return ThreadStatus{}, err
+	}
+	if msg == nil {
+		return ThreadStatus{}, nil
+	}
+	st := ThreadStatus{Found: true, Emojis: reactedEmojis(*msg, th.emojis, userID)}
+	st.Reacted = len(st.Emojis) > 0
+	return st, nil
+}
+
+// reactedEmojis は msg のリアクションのうち、userID が付けた emojis に含まれるものを返す。
+func reactedEmojis(msg slack.Message, emojis []string, userID string) []string {
+	var out []string
+	for _, r := range msg.Reactions {
+		if !slices.Contains(emojis, r.Name) {
+			continue
+		}
+		if slices.Contains(r.Users, userID) {
+			out = append(out, r.Name)
+		}
+	}
+	return out
+}
+
+// findReminder は当日のメッセージから th のリマインダーに該当するものを探す。
+// 見つからなければ nil、複数あればエラーを返す。
+func (c *Client) findReminder(ctx context.Context, channelID string, th thread) (*slack.Message, error) {
 	loc, _ := time.LoadLocation("Asia/Tokyo")
 	now := time.Now().In(loc)
 	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
@@ -66,19 +279,23 @@ func findTSByExactText(api *slack.Client, channelID string, exact string) (strin
 	var hits []slack.Message
 	cursor := ""
 	for pages := 0; pages < 5; pages++ {
-		hist, err := api.GetConversationHistory(&slack.GetConversationHistoryParameters{
-			ChannelID: channelID,
-			Oldest:    oldest,
-			Latest:    latest,
-			Inclusive: true,
-			Limit:     200,
-			Cursor:    cursor,
+		var hist *slack.GetConversationHistoryResponse
+		err := c.call(ctx, func() (err error) {
+			hist, err = c.api.GetConversationHistoryContext(ctx, &slack.GetConversationHistoryParameters{
+				ChannelID: channelID,
+				Oldest:    oldest,
+				Latest:    latest,
+				Inclusive: true,
+				Limit:     200,
+				Cursor:    cursor,
+			})
+			return err
 		})
 		if err != nil {
-			return "", fmt.Errorf("conversations.history failed: %w", err)
+			return nil, fmt.Errorf("conversations.history failed: %w", err)
 		}
 		for _, m := range hist.Messages {
-			if m.Text == exact {
+			if th.match(m.Text) {
 				hits = append(hits, m)
 			}
 		}
@@ -89,32 +306,39 @@ func findTSByExactText(api *slack.Client, channelID string, exact string) (strin
 	}
 
 	if len(hits) == 0 {
-		return "", fmt.Errorf("message not found: %q", exact)
+		return nil, nil
 	}
 	if len(hits) > 1 {
-		return "", fmt.Errorf("message not unique: %q hits=%d", exact, len(hits))
+		return nil, fmt.Errorf("%w: %s hits=%d", ErrReminderAmbiguous, th.reminder, len(hits))
 	}
-	return hits[0].Timestamp, nil
+	return &hits[0], nil
 }
 
-func resolveChannelID(api *slack.Client, input string) (string, error) {
-	if strings.HasPrefix(input, "C") || strings.HasPrefix(input, "G") {
+// resolveChannelID は設定のチャンネル（IDまたは名前）をチャンネルIDにする。
+func (c *Client) resolveChannelID(ctx context.Context) (string, error) {
+	input := c.cfg.Channel
+	if looksLikeChannelID(input) {
 		return input, nil
 	}
 	cursor := ""
 	for {
-		chans, next, err := api.GetConversations(&slack.GetConversationsParameters{
-			ExcludeArchived: true,
-			Limit:           500,
-			Cursor:          cursor,
-			Types:           []string{"public_channel", "private_channel"},
+		var chans []slack.Channel
+		var next string
+		err := c.call(ctx, func() (err error) {
+			chans, next, err = c.api.GetConversationsContext(ctx, &slack.GetConversationsParameters{
+				ExcludeArchived: true,
+				Limit:           500,
+				Cursor:          cursor,
+				Types:           []string{"public_channel", "private_channel"},
+			})
+			return err
 		})
 		if err != nil {
 			return "", fmt.Errorf("conversations.list failed: %w", err)
 		}
-		for _, c := range chans {
-			if c.Name == input {
-				return c.ID, nil
+		for _, ch := range chans {
+			if ch.Name == input {
+				return ch.ID, nil
 			}
 		}
 		if next == "" {
@@ -124,11 +348,3 @@ func resolveChannelID(api *slack.Client, input string) (string, error) {
 	}
 	return "", fmt.Errorf("channel not found: %s (set SLACK_CHANNEL to channel ID like Cxxxx)", input)
 }
-
-func mustEnv(k string) string {
-	v := strings.TrimSpace(os.Getenv(k))
-	if v == "" {
-		panic(errors.New("missing env: " + k))
-	}
-	return v
-}
@@ -0,0 +1,235 @@
+package slackkintai
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"kintai/internal/retry"
+)
+
+const (
+	fakeChannelID = "C01ABCDEF23"
+	fakeUserID    = "U0MYSELF"
+	fakeStartTS   = "1760000000.000100"
+	fakeEndTS     = "1760030000.000200"
+)
+
+// fakeSlack は Slack Web API の一部（auth.test・conversations.history・reactions.add / remove）を真似るサーバー。
+type fakeSlack struct {
+	mu sync.Mutex
+	// reactions はメッセージの ts → 付いているリアクション。
+	reactions map[string][]slack.ItemReaction
+	// added / removed は受けた reactions.add / remove の絵文字。
+	added   []string
+	removed []string
+	// fail は API のメソッド名 → 先頭から何回 500 を返すか（再試行の確認用）。
+	fail map[string]int
+	// errs は API のメソッド名 → 500 を返し終えた後に返すエラーコード（already_reacted など）。
+	errs map[string]string
+}
+
+func newFakeSlack(t *testing.T) (*fakeSlack, *Client) {
+	t.Helper()
+	fs := &fakeSlack{reactions: map[string][]slack.ItemReaction{}, fail: map[string]int{}, errs: map[string]string{}}
+	srv := httptest.NewServer(fs)
+	t.Cleanup(srv.Close)
+
+	c, err := New(Config{
+		Token:   "xoxp-1-2-3",
+		Channel: fakeChannelID,
+		Retry:   retry.Policy{BaseDelay: time.Millisecond, MaxDelay: time.Millisecond},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.api = slack.New(c.cfg.Token, slack.OptionAPIURL(srv.URL+"/"))
+	return fs, c
+}
+
+// react は ts のメッセージに userID のリアクションを足す。
+func (fs *fakeSlack) react(ts, name, userID string) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.reactions[ts] = append(fs.reactions[ts], slack.ItemReaction{Name: name, Count: 1, Users: []string{userID}})
+}
+
+func (fs *fakeSlack) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	_ = r.ParseForm()
+	method := strings.TrimPrefix(r.URL.Path, "/")
+	if fs.fail[method] > 0 {
+		fs.fail[method]--
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	if code := fs.errs[method]; code != "" {
+		writeJSON(w, map[string]any{"ok": false, "error": code})
+		return
+	}
+
+	switch method {
+	case "auth.test":
+		writeJSON(w, map[string]any{"ok": true, "user_id": fakeUserID, "user": "taro", "team": "example"})
+	case "conversations.history":
+		writeJSON(w, map[string]any{"ok": true, "messages": []map[string]any{
+			{"type": "message", "text": DefaultStartText, "ts": fakeStartTS, "reactions": fs.reactions[fakeStartTS]},
+			{"type": "message", "text": DefaultEndText, "ts": fakeEndTS, "reactions": fs.reactions[fakeEndTS]},
+		}})
+	case "reactions.add":
+		fs.added = append(fs.added, r.Form.Get("name"))
+		writeJSON(w, map[string]any{"ok": true})
+	case "reactions.remove":
+		fs.removed = append(fs.removed, r.Form.Get("name"))
+		writeJSON(w, map[string]any{"ok": true})
+	default:
+		http.Error(w, "unknown method "+method, http.StatusNotFound)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestReactStart(t *testing.T) {
+	fs, c := newFakeSlack(t)
+
+	got, err := c.ReactStart(t.Context(), "office", false)
+	if err != nil {
+		t.Fatalf("ReactStart: %v", err)
+	}
+	if want := (Reaction{Channel: fakeChannelID, Timestamp: fakeStartTS, Emoji: "shussha"}); got != want {
+		t.Errorf("ReactStart = %+v, want %+v", got, want)
+	}
+	if !reflect.DeepEqual(fs.added, []string{"shussha"}) {
+		t.Errorf("added = %v, want [shussha]", fs.added)
+	}
+}
+
+func TestReactAlreadyReacted(t *testing.T) {
+	tests := []struct {
+		name  string
+		mode  string
+		force bool
+		// skip なら *ErrAlreadyReacted で何も付けない。そうでなければ mode の絵文字を付ける。
+		skip bool
+	}{
+		{name: "already reacted", mode: "remote", force: false, skip: true},
+		{name: "force with another emoji", mode: "remote", force: true},
+		// 同じ絵文字は付け直せないので force でもスキップする
+		{name: "force with the same emoji", mode: "office", force: true, skip: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs, c := newFakeSlack(t)
+			fs.react(fakeStartTS, "shussha", fakeUserID)
+
+			got, err := c.ReactStart(t.Context(), tt.mode, tt.force)
+			if tt.skip {
+				var already *ErrAlreadyReacted
+				if !errors.As(err, &already) || !reflect.DeepEqual(already.Emojis, []string{"shussha"}) {
+					t.Fatalf("ReactStart err = %v, want ErrAlreadyReacted [shussha]", err)
+				}
+				if len(fs.added) != 0 || !got.IsZero() {
+					t.Errorf("added = %v, reaction = %+v; want nothing added", fs.added, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReactStart: %v", err)
+			}
+			if got.Emoji != "remote-start" || !reflect.DeepEqual(fs.added, []string{"remote-start"}) {
+				t.Errorf("reaction = %+v, added = %v; want remote-start", got, fs.added)
+			}
+		})
+	}
+}
+
+func TestReactIgnoresOthersReactions(t *testing.T) {
+	fs, c := newFakeSlack(t)
+	// 他人のリアクションや勤怠用でない絵文字は、反応済みとみなさない
+	fs.react(fakeEndTS, "tai-kin", "U0SOMEONE")
+	fs.react(fakeEndTS, "eyes", fakeUserID)
+
+	if _, err := c.ReactEnd(t.Context(), false); err != nil {
+		t.Fatalf("ReactEnd: %v", err)
+	}
+	if !reflect.DeepEqual(fs.added, []string{"tai-kin"}) {
+		t.Errorf("added = %v, want [tai-kin]", fs.added)
+	}
+}
+
+func TestReactAlreadyReactedOnRetry(t *testing.T) {
+	fs, c := newFakeSlack(t)
+	// 1回目は 500 で失敗したが届いていて、再試行が already_reacted になった
+	fs.fail["reactions.add"] = 1
+	fs.errs["reactions.add"] = "already_reacted"
+
+	got, err := c.ReactEnd(t.Context(), false)
+	if err != nil {
+		t.Fatalf("ReactEnd: %v", err)
+	}
+	if got.Emoji != "tai-kin" || got.Timestamp != fakeEndTS || fs.fail["reactions.add"] != 0 {
+		t.Errorf("ReactEnd = %+v, want tai-kin on the end thread after a retry", got)
+	}
+}
+
+func TestReactAlreadyReactedFirstAttempt(t *testing.T) {
+	fs, c := newFakeSlack(t)
+	// 1回目から already_reacted なら、前の試行が届いたのではないのでエラーにする
+	fs.errs["reactions.add"] = "already_reacted"
+
+	if _, err := c.ReactEnd(t.Context(), false); slackError(err) != "already_reacted" {
+		t.Errorf("ReactEnd err = %v, want already_reacted", err)
+	}
+}
+
+func TestRemoveReaction(t *testing.T) {
+	fs, c := newFakeSlack(t)
+
+	r := Reaction{Channel: fakeChannelID, Timestamp: fakeStartTS, Emoji: "shussha"}
+	if err := c.RemoveReaction(t.Context(), r); err != nil {
+		t.Fatalf("RemoveReaction: %v", err)
+	}
+	if !reflect.DeepEqual(fs.removed, []string{"shussha"}) {
+		t.Errorf("removed = %v, want [shussha]", fs.removed)
+	}
+
+	// 付けなかったリアクションは外さない
+	if err := c.RemoveReaction(t.Context(), Reaction{}); err != nil || len(fs.removed) != 1 {
+		t.Errorf("RemoveReaction(zero) = %v, removed = %v; want no call", err, fs.removed)
+	}
+}
+
+func TestRemoveReactionNoReactionOnRetry(t *testing.T) {
+	fs, c := newFakeSlack(t)
+	// 1回目は 500 で失敗したが届いていて、再試行が no_reaction になった
+	fs.fail["reactions.remove"] = 1
+	fs.errs["reactions.remove"] = "no_reaction"
+
+	r := Reaction{Channel: fakeChannelID, Timestamp: fakeEndTS, Emoji: "tai-kin"}
+	if err := c.RemoveReaction(t.Context(), r); err != nil || fs.fail["reactions.remove"] != 0 {
+		t.Errorf("RemoveReaction = %v, want success after a retry", err)
+	}
+}
+
+func TestRemoveReactionNoReactionFirstAttempt(t *testing.T) {
+	fs, c := newFakeSlack(t)
+	// 1回目から no_reaction なら、外すリアクションが無かったのでエラーにする
+	fs.errs["reactions.remove"] = "no_reaction"
+
+	r := Reaction{Channel: fakeChannelID, Timestamp: fakeEndTS, Emoji: "tai-kin"}
+	if err := c.RemoveReaction(t.Context(), r); slackError(err) != "no_reaction" {
+		t.Errorf("RemoveReaction err = %v, want no_reaction", err)
+	}
+}
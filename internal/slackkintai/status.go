@@ -0,0 +1,52 @@
+package slackkintai
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// UserStatus は Slack のプロフィールに設定するステータス。
+type UserStatus struct {
+	// Text はステータスの文言（例: "休憩中"）。
+	Text string
+	// Emoji はコロンなしの絵文字名（例: "coffee"）。
+	Emoji string
+	// Expiration は設定してから自動で消えるまでの時間。0 なら消えない。
+	Expiration time.Duration
+}
+
+// IsZero は文言も絵文字も設定されていないかを返す。
+func (s UserStatus) IsZero() bool {
+	return s.Text == "" && s.Emoji == ""
+}
+
+// SetStatus は自分の Slack ステータスを st にする。users.profile:write 権限が必要。
+func (c *Client) SetStatus(ctx context.Context, st UserStatus) error {
+	var emoji string
+	if st.Emoji != "" {
+		emoji = ":" + st.Emoji + ":"
+	}
+	var exp int64
+	if st.Expiration > 0 {
+		exp = time.Now().Add(st.Expiration).Unix()
+	}
+	err := c.call(ctx, func() error {
+		return c.api.SetUserCustomStatusContext(ctx, st.Text, emoji, exp)
+	})
+	if err != nil {
+		return fmt.Errorf("users.profile.set failed: %w", err)
+	}
+	return nil
+}
+
+// ClearStatus は自分の Slack ステータスを消す。
+func (c *Client) ClearStatus(ctx context.Context) error {
+	err := c.call(ctx, func() error {
+		return c.api.UnsetUserCustomStatusContext(ctx)
+	})
+	if err != nil {
+		return fmt.Errorf("users.profile.set failed: %w", err)
+	}
+	return nil
+}